@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/apikey"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/config"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+)
+
+const usage = `Usage: apikey <command> [flags]
+
+Commands:
+  create  -name NAME -scopes articles:read,articles:write [-ttl 720h]
+  list
+  revoke  -id ID
+  rotate  -id ID [-overlap 24h]
+`
+
+func main() {
+	logger.Init()
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	db, err := database.NewMySQL(cfg.DatabaseURL)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("failed connect DB")
+	}
+	defer db.Close()
+
+	svc := apikey.NewService(apikey.NewMySQLRepository(db))
+	ctx := context.Background()
+
+	cmd, args := os.Args[1], os.Args[2:]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	switch cmd {
+	case "create":
+		name := fs.String("name", "", "key name, e.g. the client using it")
+		scopes := fs.String("scopes", apikey.ScopeRead, "comma separated scopes: "+strings.Join(apikey.Scopes, ", "))
+		ttl := fs.Duration("ttl", 0, "key lifetime, 0 = never expires")
+		_ = fs.Parse(args)
+		if strings.TrimSpace(*name) == "" {
+			logger.Log.Fatal("-name is required")
+		}
+		k, raw, err := svc.Create(ctx, strings.TrimSpace(*name), splitScopes(*scopes), *ttl)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("create api key failed")
+		}
+		printSecret(k, raw)
+	case "list":
+		_ = fs.Parse(args)
+		keys, err := svc.List(ctx)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("list api keys failed")
+		}
+		printKeys(keys)
+	case "revoke":
+		id := fs.Int64("id", 0, "key id")
+		_ = fs.Parse(args)
+		if err := svc.Revoke(ctx, *id); err != nil {
+			logger.Log.WithError(err).Fatal("revoke api key failed")
+		}
+		fmt.Printf("key %d revoked\n", *id)
+	case "rotate":
+		id := fs.Int64("id", 0, "key id")
+		overlap := fs.Duration("overlap", 24*time.Hour, "how long the old key keeps working")
+		_ = fs.Parse(args)
+		k, raw, err := svc.Rotate(ctx, *id, *overlap)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("rotate api key failed")
+		}
+		fmt.Printf("key %d replaced, old key expires in %s\n", *id, *overlap)
+		printSecret(k, raw)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func splitScopes(v string) []string {
+	res := make([]string, 0)
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func printSecret(k apikey.APIKey, raw string) {
+	fmt.Printf("id:      %d\nname:    %s\nscopes:  %s\nexpires: %s\n\n", k.ID, k.Name, strings.Join(k.Scopes, ","), formatTime(k.ExpiresAt))
+	fmt.Printf("%s\n\nStore this key now, it cannot be shown again.\n", raw)
+}
+
+func printKeys(keys []apikey.APIKey) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED\tSTATUS")
+	now := time.Now()
+	for _, k := range keys {
+		status := "active"
+		switch {
+		case k.RevokedAt != nil:
+			status = "revoked"
+		case !k.Active(now):
+			status = "expired"
+		case k.ReplacedBy != nil:
+			status = fmt.Sprintf("rotated (-> %d)", *k.ReplacedBy)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s…\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(k.Scopes, ","), formatTime(k.ExpiresAt), formatTime(k.LastUsedAt), status)
+	}
+	_ = w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
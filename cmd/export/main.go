@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/article"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/config"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+)
+
+const usage = `Usage: export [flags]
+
+Writes the articles matching the filters to a file, in the same formats as
+GET /articles/export. Filter flags take the values of the list query params.
+
+Flags:
+`
+
+// filterFlags are the ListFilter query params exposed as flags
+var filterFlags = []string{"title", "content", "status", "status!", "category", "category!", "tag", "tag_match", "created_from", "created_to", "updated_since"}
+
+func main() {
+	logger.Init()
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", article.ExportCSV, "csv, jsonl or ndjson")
+	out := fs.String("out", "", `output file, "-" for stdout (default articles-<time>.<format>)`)
+	values := make(map[string]*string, len(filterFlags))
+	for _, key := range filterFlags {
+		values[key] = fs.String(key, "", "filter "+key)
+	}
+	_ = fs.Parse(os.Args[1:])
+
+	filter, errs := article.ParseListFilter(func(key string) string {
+		if v, ok := values[key]; ok {
+			return *v
+		}
+		return ""
+	})
+	if len(errs) > 0 {
+		for _, fe := range errs {
+			fmt.Fprintf(os.Stderr, "-%s: %s\n", fe.Field, fe.Message)
+		}
+		os.Exit(2)
+	}
+	if !article.IsValidExportFormat(*format) {
+		fmt.Fprintln(os.Stderr, article.ErrUnknownExportFormat)
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = fmt.Sprintf("articles-%s.%s", time.Now().UTC().Format("20060102-150405"), *format)
+	}
+
+	cfg := config.Load()
+	db, err := database.NewMySQL(cfg.DatabaseURL)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("failed connect DB")
+	}
+	defer db.Close()
+
+	// exporting only reads articles, the write dependencies stay unset
+	svc := article.NewService(article.NewMySQLRepository(db), nil, nil, nil, cfg.CursorSecret)
+
+	n, err := export(svc, filter, *format, *out)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("export articles failed")
+	}
+	logger.Log.WithFields(map[string]interface{}{"articles": n, "out": *out}).Info("articles exported")
+}
+
+// export writes to out, removing a partly written file when it fails
+func export(svc *article.Service, filter article.ListFilter, format, out string) (int, error) {
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if out != "-" {
+		var err error
+		if f, err = os.Create(out); err != nil {
+			return 0, err
+		}
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	n, err := svc.Export(context.Background(), filter, format, bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if f != nil {
+		if errClose := f.Close(); err == nil {
+			err = errClose
+		}
+		if err != nil {
+			_ = os.Remove(out)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/article"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/config"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+const usage = `Usage: import [flags] FILE
+
+Creates or updates the articles of a csv or jsonl file, like
+POST /articles/import. Writes are recorded in the audit log as "cli:import"
+and trigger webhooks like any other change.
+
+Flags:
+`
+
+// cliActor is the audit actor of imported changes
+const cliActor = "cli:import"
+
+func main() {
+	logger.Init()
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		fs.PrintDefaults()
+	}
+	format := fs.String("format", "", "csv, jsonl or ndjson (default from the file extension)")
+	key := fs.String("key", article.ImportKeyExternalID, "match existing articles by external_id or slug")
+	dryRun := fs.Bool("dry-run", false, "validate and match rows without writing")
+	batchSize := fs.Int("batch-size", article.DefaultImportBatchSize, "rows written per transaction")
+	authorID := fs.Int64("author-id", 0, "author of created articles, 0 = none")
+	report := fs.String("report", "", "write the failed rows as CSV to this file")
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("open import file failed")
+	}
+	defer f.Close()
+
+	cfg := config.Load()
+	db, err := database.NewMySQL(cfg.DatabaseURL)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("failed connect DB")
+	}
+	defer db.Close()
+
+	svc := article.NewService(article.NewMySQLRepository(db), category.NewMySQLRepository(db), audit.NewMySQLRepository(db), webhook.NewMySQLRepository(db), cfg.CursorSecret)
+	importer := article.NewImporter(svc, validatorpkg.NewValidator())
+	ctx := audit.WithSource(context.Background(), audit.Source{Actor: cliActor})
+
+	rep, err := importer.Import(ctx, f, article.ImportOptions{
+		Format:    strings.ToLower(*format),
+		Key:       *key,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+		AuthorID:  *authorID,
+	})
+	fmt.Printf("rows: %d, created: %d, updated: %d, unchanged: %d, failed: %d", rep.Total, rep.Created, rep.Updated, rep.Unchanged, rep.Failed)
+	if rep.DryRun {
+		fmt.Print(" (dry run, nothing was written)")
+	}
+	fmt.Println()
+	if err != nil {
+		logger.Log.WithError(err).Fatal("import articles failed")
+	}
+
+	if *report != "" && rep.Failed > 0 {
+		if err := writeReport(*report, rep); err != nil {
+			logger.Log.WithError(err).Fatal("write import report failed")
+		}
+		fmt.Printf("failed rows written to %s\n", *report)
+	} else {
+		for _, e := range rep.Errors {
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Error)
+			for _, fe := range e.Errors {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", fe.Field, fe.Message)
+			}
+		}
+	}
+	if rep.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeReport(path string, rep article.ImportReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := article.WriteImportErrors(f, rep); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
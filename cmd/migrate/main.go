@@ -4,11 +4,13 @@ import (
 	"flag"
 	"os"
 
+	"context"
 	"database/sql"
 
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
 
-	_ "github.com/go-sql-driver/mysql"
+	gomysql "github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -19,7 +21,7 @@ func main() {
 	logger.Init()
 
 	dir := flag.String("dir", "migrations", "directory of migration files")
-	action := flag.String("action", "up", "migration action: up or down")
+	action := flag.String("action", "up", "migration action: up, down, force or reslug-categories")
 	flag.Parse()
 
 	_ = godotenv.Load()
@@ -29,6 +31,14 @@ func main() {
 		logger.Log.Fatal("DATABASE_URL is empty")
 	}
 
+	// migration files may contain several statements
+	dsnCfg, err := gomysql.ParseDSN(dsn)
+	if err != nil {
+		logger.Log.WithError(err).Fatal("invalid DATABASE_URL")
+	}
+	dsnCfg.MultiStatements = true
+	dsn = dsnCfg.FormatDSN()
+
 	logger.Log.WithFields(map[string]interface{}{"dir": *dir, "action": *action}).Info("starting migration")
 
 	db, err := sql.Open("mysql", dsn)
@@ -74,9 +84,28 @@ func main() {
 		} else {
 			logger.Log.Info("migrate force: success")
 		}
+	case "reslug-categories":
+		reslugCategories(db)
 	default:
 		logger.Log.WithField("action", *action).Fatal("unknown action")
 	}
 
 	logger.Log.Info("migration finished")
 }
+
+// reslugCategories recomputes the category slugs with slug.Make, the
+// algorithm of the lookups; migration 0008 could only approximate it in SQL.
+// It is a one-off run by hand after upgrading past 0008, or after slug.Make
+// changed, since it renames slugs that clients may have bookmarked.
+func reslugCategories(db *sql.DB) {
+	svc := category.NewService(category.NewMySQLRepository(db), nil)
+	updated, skipped, err := svc.Reslug(context.Background())
+	if err != nil {
+		logger.Log.WithError(err).Fatal("reslug categories failed")
+	}
+	for _, c := range skipped {
+		logger.Log.WithFields(map[string]interface{}{"id": c.ID, "name": c.Name, "slug": c.Slug}).
+			Warn("reslug categories: slug already used by another category, kept")
+	}
+	logger.Log.WithField("updated", updated).Info("reslug categories: done")
+}
@@ -1,10 +1,21 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/apikey"
 	"github.com/ranggakrisnaa/sharing-vision-backend/internal/article"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
 	"github.com/ranggakrisnaa/sharing-vision-backend/internal/router"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/config"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
@@ -15,23 +26,97 @@ func main() {
 	cfg := config.Load()
 	logger.Init()
 
-	db, err := database.NewMySQL(cfg.DatabaseURL)
-	if err != nil {
-		logger.Log.WithError(err).Fatal("failed connect DB")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var articleRepository article.Repository
+	var categoryRepository category.Repository
+	var userRepository auth.Repository
+	var apiKeyRepository apikey.Repository
+	var auditRepository audit.Repository
+	var webhookRepository webhook.Repository
+	switch cfg.StorageDriver {
+	case "memory":
+		logger.Log.Warn("using in-memory storage, data will be lost on restart")
+		categoryRepository = category.NewMemoryRepository()
+		userRepository = auth.NewMemoryRepository()
+		apiKeyRepository = apikey.NewMemoryRepository()
+		auditRepository = audit.NewMemoryRepository()
+		webhookRepository = webhook.NewMemoryRepository()
+		articleRepository = article.NewMemoryRepository(categoryRepository)
+	case "mysql":
+		db, err := database.NewMySQL(cfg.DatabaseURL)
+		if err != nil {
+			logger.Log.WithError(err).Fatal("failed connect DB")
+		}
+		defer db.Close()
+		articleRepository = article.NewMySQLRepository(db)
+		categoryRepository = category.NewMySQLRepository(db)
+		userRepository = auth.NewMySQLRepository(db)
+		apiKeyRepository = apikey.NewMySQLRepository(db)
+		auditRepository = audit.NewMySQLRepository(db)
+		webhookRepository = webhook.NewMySQLRepository(db)
+	default:
+		logger.Log.WithField("driver", cfg.StorageDriver).Fatal("unknown storage driver")
 	}
-	defer db.Close()
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{BodyLimit: cfg.BodyLimitMB << 20})
 
 	// Register routes
-	articleRepository := article.NewMySQLRepository(db)
-	articleService := article.NewService(articleRepository)
-	articleHandler := article.NewHandler(articleService, validatorpkg.NewValidator())
-	router.Register(app, articleHandler)
+	validator := validatorpkg.NewValidator()
+	authService := auth.NewService(userRepository, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.AdminEmail)
+	if promoted, err := authService.EnsureAdmin(ctx); err != nil {
+		logger.Log.WithError(err).Fatal("promote admin failed")
+	} else if promoted {
+		logger.Log.WithField("email", cfg.AdminEmail).Info("user promoted to admin")
+	}
+	authHandler := auth.NewHandler(authService, validator)
+	articleService := article.NewService(articleRepository, categoryRepository, auditRepository, webhookRepository, cfg.CursorSecret)
+	articleHandler := article.NewHandler(articleService, article.NewPolicy(article.DefaultPolicyRules), validator)
+	categoryHandler := category.NewHandler(category.NewService(categoryRepository, articleRepository), validator)
+	auditHandler := audit.NewHandler(audit.NewService(auditRepository))
+	webhookHandler := webhook.NewHandler(webhook.NewService(webhookRepository), validator)
+	router.Register(app, authService, apikey.NewService(apiKeyRepository), authHandler, articleHandler, categoryHandler, auditHandler, webhookHandler)
+
+	// Background jobs, waited for on shutdown so no write is cut off mid-way
+	var workers sync.WaitGroup
+	if cfg.TrashRetention > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			article.NewPurger(articleService, cfg.TrashRetention, cfg.TrashPurgeInterval).Run(ctx)
+		}()
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		article.NewScheduler(articleService, cfg.SchedulerInterval).Run(ctx)
+	}()
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		webhook.NewDispatcher(webhookRepository, webhook.DispatcherConfig{
+			Interval:    cfg.WebhookInterval,
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			Timeout:     cfg.WebhookTimeout,
+		}).Run(ctx)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		logger.Log.Info("shutting down server")
+		// end open article streams, Shutdown waits for every connection
+		articleService.Changes().Close()
+		if err := app.Shutdown(); err != nil {
+			logger.Log.WithError(err).Error("server shutdown failed")
+		}
+	}()
 
 	port := cfg.Port
 	logger.Log.WithField("port", port).Info("server listening")
 	if err := app.Listen(":" + port); err != nil {
 		logger.Log.WithError(err).Fatal("server crashed")
 	}
+	workers.Wait()
+	logger.Log.Info("server stopped")
 }
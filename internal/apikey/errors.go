@@ -0,0 +1,9 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrInvalidKey   = errors.New("API key tidak valid, sudah dicabut atau kedaluwarsa")
+	ErrUnknownScope = errors.New("scope tidak dikenal")
+	ErrRevoked      = errors.New("API key sudah dicabut")
+)
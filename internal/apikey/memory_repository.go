@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository menyimpan API key di memory untuk STORAGE_DRIVER=memory.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	items  map[int64]APIKey
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{items: make(map[int64]APIKey)}
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, k APIKey) (APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(k), nil
+}
+
+// insert stores k under a new id. Caller must hold r.mu.
+func (r *MemoryRepository) insert(k APIKey) APIKey {
+	r.nextID++
+	k.ID = r.nextID
+	k.CreatedAt = time.Now().Truncate(time.Second)
+	k.LastUsedAt, k.RevokedAt, k.ReplacedBy = nil, nil, nil
+	r.items[k.ID] = k
+	return k
+}
+
+func (r *MemoryRepository) List(ctx context.Context) ([]APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]APIKey, 0, len(r.items))
+	for _, k := range r.items {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID > res[j].ID })
+	return res, nil
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	k, ok := r.items[id]
+	if !ok {
+		return APIKey{}, sql.ErrNoRows
+	}
+	return k, nil
+}
+
+func (r *MemoryRepository) FindByHash(ctx context.Context, hash string) (APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.items {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+	return APIKey{}, sql.ErrNoRows
+}
+
+func (r *MemoryRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.items[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if k.RevokedAt != nil {
+		return ErrRevoked
+	}
+	k.RevokedAt = &at
+	r.items[id] = k
+	return nil
+}
+
+func (r *MemoryRepository) Rotate(ctx context.Context, id int64, next APIKey, oldExpiresAt time.Time) (APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.items[id]
+	if !ok || old.RevokedAt != nil {
+		return APIKey{}, ErrRevoked
+	}
+	k := r.insert(next)
+	if old.ExpiresAt == nil || old.ExpiresAt.After(oldExpiresAt) {
+		old.ExpiresAt = &oldExpiresAt
+	}
+	old.ReplacedBy = &k.ID
+	r.items[id] = old
+	return k, nil
+}
+
+func (r *MemoryRepository) Touch(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if k, ok := r.items[id]; ok {
+		k.LastUsedAt = &at
+		r.items[id] = k
+	}
+	return nil
+}
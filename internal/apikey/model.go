@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+)
+
+const (
+	ScopeRead  = "articles:read"
+	ScopeWrite = "articles:write"
+	// ScopeAdmin also allows permanent deletes
+	ScopeAdmin = "articles:admin"
+)
+
+// Scopes lists every valid scope, from narrowest to broadest
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+type APIKey struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Prefix is the start of the key, kept to recognise it in listings
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// ReplacedBy points at the key created when this one was rotated
+	ReplacedBy *int64    `json:"replaced_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Active reports whether the key can still authenticate at now
+func (k APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// Role maps the broadest scope of the key to the user role the article
+// policy checks: admin → admin, write → editor, read → reader.
+func (k APIKey) Role() auth.Role {
+	switch {
+	case slices.Contains(k.Scopes, ScopeAdmin):
+		return auth.RoleAdmin
+	case slices.Contains(k.Scopes, ScopeWrite):
+		return auth.RoleEditor
+	}
+	return auth.RoleReader
+}
+
+// Principal is the caller identity a request authenticated with this key gets
+func (k APIKey) Principal() auth.Principal {
+	return auth.Principal{Name: k.Name, Role: k.Role(), APIKeyID: k.ID}
+}
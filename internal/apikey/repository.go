@@ -0,0 +1,155 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type Repository interface {
+	Insert(ctx context.Context, k APIKey) (APIKey, error)
+	// List returns every key, newest first
+	List(ctx context.Context) ([]APIKey, error)
+	FindByID(ctx context.Context, id int64) (APIKey, error)
+	FindByHash(ctx context.Context, hash string) (APIKey, error)
+	Revoke(ctx context.Context, id int64, at time.Time) error
+	// Rotate stores next and lets key id expire at oldExpiresAt, atomically
+	Rotate(ctx context.Context, id int64, next APIKey, oldExpiresAt time.Time) (APIKey, error)
+	Touch(ctx context.Context, id int64, at time.Time) error
+}
+
+const keyColumns = `id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, replaced_by, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanKey(s rowScanner) (APIKey, error) {
+	var k APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var replacedBy sql.NullInt64
+	err := s.Scan(&k.ID, &k.Name, &k.Prefix, &k.Hash, &scopes, &expiresAt, &lastUsedAt, &revokedAt, &replacedBy, &k.CreatedAt)
+	if err != nil {
+		return APIKey{}, err
+	}
+	k.Scopes = strings.Split(scopes, ",")
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	if replacedBy.Valid {
+		k.ReplacedBy = &replacedBy.Int64
+	}
+	return k, nil
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) *MySQLRepository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) Insert(ctx context.Context, k APIKey) (APIKey, error) {
+	id, err := insertKey(ctx, r.db, k)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertKey(ctx context.Context, db execer, k APIKey) (int64, error) {
+	q := `INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := db.ExecContext(ctx, q, k.Name, k.Prefix, k.Hash, strings.Join(k.Scopes, ","), k.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (r *MySQLRepository) List(ctx context.Context) ([]APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+keyColumns+` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		return []APIKey{}, err
+	}
+	defer rows.Close()
+
+	res := make([]APIKey, 0)
+	for rows.Next() {
+		k, errScan := scanKey(rows)
+		if errScan != nil {
+			return []APIKey{}, errScan
+		}
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+func (r *MySQLRepository) FindByID(ctx context.Context, id int64) (APIKey, error) {
+	return scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE id = ?`, id))
+}
+
+func (r *MySQLRepository) FindByHash(ctx context.Context, hash string) (APIKey, error) {
+	return scanKey(r.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM api_keys WHERE key_hash = ?`, hash))
+}
+
+func (r *MySQLRepository) Revoke(ctx context.Context, id int64, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, at, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrRevoked
+	}
+	return nil
+}
+
+func (r *MySQLRepository) Rotate(ctx context.Context, id int64, next APIKey, oldExpiresAt time.Time) (APIKey, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return APIKey{}, err
+	}
+	defer tx.Rollback()
+
+	newID, err := insertKey(ctx, tx, next)
+	if err != nil {
+		return APIKey{}, err
+	}
+	// never extend the old key past its own expiry
+	q := `
+    UPDATE api_keys
+    SET expires_at = IF(expires_at IS NULL OR expires_at > ?, ?, expires_at), replaced_by = ?
+    WHERE id = ? AND revoked_at IS NULL
+    `
+	res, err := tx.ExecContext(ctx, q, oldExpiresAt, oldExpiresAt, newID, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return APIKey{}, ErrRevoked
+	}
+	if err := tx.Commit(); err != nil {
+		return APIKey{}, err
+	}
+	return r.FindByID(ctx, newID)
+}
+
+func (r *MySQLRepository) Touch(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, at, id)
+	return err
+}
@@ -0,0 +1,245 @@
+package article
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// bulkItem is a validated bulk operation with its decoded data
+type bulkItem struct {
+	BulkOperation
+	create CreateArticleRequest
+	update UpdateArticleRequest
+}
+
+// bulkError stops an atomic bulk request at the operation that failed
+type bulkError struct {
+	result BulkResult
+}
+
+func (e *bulkError) Error() string {
+	return fmt.Sprintf("operasi ke-%d (%s) gagal: %s", e.result.Index, e.result.Op, e.result.Error)
+}
+
+// bulk applies a list of create, update, delete and set_status operations.
+// Every operation is validated first; an atomic request with an invalid
+// operation is rejected as a whole, otherwise invalid items are reported in
+// their result. Each operation is authorized like its single-article endpoint.
+func (h *Handler) bulk(c *fiber.Ctx) error {
+	var req BulkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	if len(req.Operations) == 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "operations wajib diisi")
+	}
+	if len(req.Operations) > MaxBulkOperations {
+		return response.Fail(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("operations maksimal %d item", MaxBulkOperations))
+	}
+
+	items := make([]bulkItem, len(req.Operations))
+	results := make([]BulkResult, len(req.Operations))
+	invalid := make([]validatorpkg.FieldError, 0)
+	for i, op := range req.Operations {
+		var errs []validatorpkg.FieldError
+		items[i], errs = h.parseBulkOperation(op)
+		results[i] = BulkResult{Index: i, Op: op.Op, ID: op.ID}
+		if len(errs) == 0 {
+			continue
+		}
+		results[i].Status = fiber.StatusUnprocessableEntity
+		results[i].Error = "operasi tidak valid"
+		results[i].Errors = errs
+		for _, fe := range errs {
+			fe.Field = fmt.Sprintf("operations[%d].%s", i, fe.Field)
+			invalid = append(invalid, fe)
+		}
+	}
+
+	ctx := mutationContext(c)
+	p, _ := auth.PrincipalFrom(c)
+	if req.Atomic {
+		if len(invalid) > 0 {
+			return response.Fail(c, fiber.StatusUnprocessableEntity, invalid)
+		}
+		err := h.svc.withinTx(ctx, func(ctx context.Context) error {
+			for i := range items {
+				results[i] = h.runBulkOperation(ctx, p, i, items[i])
+				if !results[i].Success {
+					return &bulkError{result: results[i]}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			var be *bulkError
+			if errors.As(err, &be) {
+				return response.FailWithErrors(c, be.result.Status, "bulk dibatalkan, "+be.Error(), []interface{}{be.result})
+			}
+			return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+		}
+		return response.Success(c, fiber.StatusOK, fiber.Map{"results": results}, "bulk operations applied successfully")
+	}
+
+	succeeded := 0
+	for i := range items {
+		if len(results[i].Errors) > 0 {
+			continue
+		}
+		if results[i] = h.runBulkOperation(ctx, p, i, items[i]); results[i].Success {
+			succeeded++
+		}
+	}
+	return response.Success(c, fiber.StatusOK, fiber.Map{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	}, "bulk operations processed")
+}
+
+// parseBulkOperation validates op and decodes its data; field names in the
+// returned errors are relative to the operation
+func (h *Handler) parseBulkOperation(op BulkOperation) (bulkItem, []validatorpkg.FieldError) {
+	item := bulkItem{BulkOperation: op}
+	errs, _ := h.validator.ValidateStructDetailed(op)
+	if op.ID == 0 && (op.Op == BulkUpdate || op.Op == BulkDelete || op.Op == BulkSetStatus) {
+		errs = append(errs, validatorpkg.FieldError{Field: "id", Message: "id wajib diisi", Tag: "required"})
+	}
+	if op.Op == BulkSetStatus && op.Status == "" {
+		errs = append(errs, validatorpkg.FieldError{Field: "status", Message: "status wajib diisi", Tag: "required"})
+	}
+
+	var data interface{}
+	switch op.Op {
+	case BulkCreate:
+		data = &item.create
+	case BulkUpdate:
+		data = &item.update
+	default:
+		return item, errs
+	}
+	if len(op.Data) == 0 {
+		return item, append(errs, validatorpkg.FieldError{Field: "data", Message: "data wajib diisi", Tag: "required"})
+	}
+	if err := json.Unmarshal(op.Data, data); err != nil {
+		return item, append(errs, validatorpkg.FieldError{Field: "data", Message: "data bukan JSON object yang valid", Tag: "json"})
+	}
+	dataErrs, _ := h.validator.ValidateStructDetailed(data)
+	if op.Op == BulkCreate {
+		dataErrs = append(dataErrs, entryStatusErrors("status", item.create.Status)...)
+		dataErrs = append(dataErrs, tagErrors(item.create.Tags)...)
+	} else {
+		dataErrs = append(dataErrs, tagErrors(item.update.Tags)...)
+	}
+	for _, fe := range dataErrs {
+		fe.Field = "data." + fe.Field
+		errs = append(errs, fe)
+	}
+	return item, errs
+}
+
+// runBulkOperation authorizes and applies one valid operation
+func (h *Handler) runBulkOperation(ctx context.Context, p auth.Principal, index int, item bulkItem) BulkResult {
+	res := BulkResult{Index: index, Op: item.Op, ID: item.ID}
+	art, err := h.applyBulkOperation(ctx, p, item)
+	if err != nil {
+		res.Status, res.Error = bulkErrorStatus(err), err.Error()
+		if err == sql.ErrNoRows {
+			res.Error = "article tidak ditemukan"
+		}
+		return res
+	}
+	res.Success, res.Status = true, fiber.StatusOK
+	if item.Op == BulkCreate {
+		res.Status = fiber.StatusCreated
+	}
+	if art != nil {
+		res.ID, res.Data = art.ID, art
+	}
+	return res
+}
+
+// applyBulkOperation returns the written article, nil after a permanent delete
+func (h *Handler) applyBulkOperation(ctx context.Context, p auth.Principal, item bulkItem) (*Article, error) {
+	actor := audit.SourceFrom(ctx).Actor
+	if item.Op == BulkCreate {
+		draft := Article{AuthorID: &p.UserID, Status: StatusDraft}
+		if err := h.authorizeWrite(p, ActionCreate, draft, item.create.Status); err != nil {
+			return nil, err
+		}
+		art, err := h.svc.Create(ctx, item.create, p.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &art, nil
+	}
+
+	curr, err := h.svc.GetByID(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	var art Article
+	switch item.Op {
+	case BulkUpdate:
+		if err := h.authorizeWrite(p, ActionUpdate, curr, item.update.Status); err != nil {
+			return nil, err
+		}
+		art, err = h.svc.Update(ctx, item.ID, item.update, item.Version, actor)
+	case BulkDelete:
+		if item.Permanent {
+			if err := h.authorizeWrite(p, ActionPurge, curr, ""); err != nil {
+				return nil, err
+			}
+			return nil, h.svc.Delete(ctx, item.ID)
+		}
+		if err := h.authorizeWrite(p, ActionTrash, curr, ""); err != nil {
+			return nil, err
+		}
+		art, err = h.svc.Trash(ctx, item.ID, actor)
+	case BulkSetStatus:
+		if curr.Status == item.Status {
+			return &curr, nil
+		}
+		t, ok := transitionTo(curr.Status, item.Status)
+		if !ok {
+			return nil, &TransitionError{From: curr.Status, To: item.Status, Allowed: NextTransitions(curr.Status)}
+		}
+		if err := h.authorizeWrite(p, transitionAction(t), curr, t.To); err != nil {
+			return nil, err
+		}
+		art, err = h.svc.Transition(ctx, item.ID, t.Action, TransitionRequest{Comment: item.Comment, PublishAt: item.PublishAt}, actor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &art, nil
+}
+
+// bulkErrorStatus maps an operation error to the status its single-article
+// endpoint would respond with
+func bulkErrorStatus(err error) int {
+	var te *TransitionError
+	switch {
+	case err == sql.ErrNoRows:
+		return fiber.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return fiber.StatusForbidden
+	case errors.As(err, &te), err == ErrSlugConflict, err == ErrExternalIDConflict, err == ErrAlreadyTrashed, err == ErrNotTrashed:
+		return fiber.StatusConflict
+	case err == ErrVersionMismatch:
+		return fiber.StatusPreconditionFailed
+	case err == ErrUnknownCategory, err == ErrInvalidSchedule, err == ErrCommentRequired, err == ErrInvalidEntryStatus:
+		return fiber.StatusUnprocessableEntity
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,109 @@
+package article
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// newTestApp mounts the article endpoints of env, authenticated as p
+func newTestApp(env *testEnv, p auth.Principal) *fiber.App {
+	app := fiber.New()
+	h := NewHandler(env.svc, NewPolicy(DefaultPolicyRules), validatorpkg.NewValidator())
+	h.Register(app.Group("/articles"), func(c *fiber.Ctx) error {
+		auth.SetPrincipal(c, p)
+		return c.Next()
+	})
+	return app
+}
+
+func postBulk(t *testing.T, app *fiber.App, req BulkRequest) (int, response.Response) {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest(fiber.MethodPost, "/articles/bulk", bytes.NewReader(body))
+	r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	res, err := app.Test(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out response.Response
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, out
+}
+
+func TestAtomicBulkRollsBack(t *testing.T) {
+	env := newTestEnv(t)
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleEditor})
+	a := env.create(t, "Bulk target article")
+	commitsBefore, _ := env.repo.outcomes()
+	sub, _, _ := env.svc.Changes().Subscribe(StreamFilter{}, 0)
+	defer env.svc.Changes().Unsubscribe(sub)
+
+	create, _ := json.Marshal(validRequest("Bulk created article"))
+	status, out := postBulk(t, app, BulkRequest{Atomic: true, Operations: []BulkOperation{
+		{Op: BulkCreate, Data: create},
+		// a draft has to go through review first
+		{Op: BulkSetStatus, ID: a.ID, Status: StatusPublish},
+		{Op: BulkDelete, ID: a.ID, Permanent: true},
+	}})
+	if status != fiber.StatusConflict || out.Success {
+		t.Fatalf("status = %d, response %+v, want 409", status, out)
+	}
+	if len(out.Errors) != 1 {
+		t.Fatalf("errors = %+v, want the failed operation", out.Errors)
+	}
+	if failed := out.Errors[0].(map[string]interface{}); failed["index"] != float64(1) {
+		t.Errorf("failed operation = %v, want index 1", failed)
+	}
+
+	commits, rollbacks := env.repo.outcomes()
+	if commits != commitsBefore || rollbacks != 1 {
+		t.Errorf("transactions = %d committed, %d rolled back, want only a rollback", commits-commitsBefore, rollbacks)
+	}
+	// nothing of the rolled back request reaches the stream
+	if got := drain(sub); len(got) != 0 {
+		t.Errorf("stream events = %d, want none", len(got))
+	}
+	// the create before the failing operation is undone
+	ctx := context.Background()
+	list, _, err := env.svc.List(ctx, ListParams{Filter: ListFilter{Title: "Bulk created article"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("rolled back create still listed: %+v", list)
+	}
+	if _, err := env.svc.GetByID(ctx, a.ID+1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("rolled back create found by id: %v", err)
+	}
+	// the operation after the failing one never ran
+	got, err := env.svc.GetByID(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("article deleted by a later operation: %v", err)
+	}
+	if got.Version != a.Version || got.Status != a.Status {
+		t.Errorf("article = version %d %s, want unchanged version %d %s", got.Version, got.Status, a.Version, a.Status)
+	}
+
+	// without atomic every operation stands on its own
+	status, out = postBulk(t, app, BulkRequest{Operations: []BulkOperation{
+		{Op: BulkSetStatus, ID: a.ID, Status: StatusPublish},
+		{Op: BulkSetStatus, ID: a.ID, Status: StatusReview},
+	}})
+	data := out.Data.(map[string]interface{})
+	if status != fiber.StatusOK || data["succeeded"] != float64(1) || data["failed"] != float64(1) {
+		t.Errorf("non atomic = %d %v", status, data)
+	}
+}
@@ -0,0 +1,30 @@
+package article
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+// CategoryLookup resolves the managed categories articles belong to.
+// category.Repository satisfies it.
+type CategoryLookup interface {
+	FindByID(ctx context.Context, id int64) (category.Category, error)
+	FindBySlug(ctx context.Context, slug string) (category.Category, error)
+}
+
+// resolveCategory finds the category a client refers to by name or slug,
+// so "Tech" and "tech" land in the same category.
+func (s *Service) resolveCategory(ctx context.Context, ref string) (category.Category, error) {
+	key := slug.Make(ref)
+	if key == "" {
+		return category.Category{}, ErrUnknownCategory
+	}
+	c, err := s.categories.FindBySlug(ctx, key)
+	if err == sql.ErrNoRows {
+		return category.Category{}, ErrUnknownCategory
+	}
+	return c, err
+}
@@ -0,0 +1,73 @@
+package article
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("cursor tidak valid")
+
+// Cursor marks a position in keyset pagination. It is handed to clients as an
+// opaque, signed token so they cannot craft arbitrary positions.
+type Cursor struct {
+	// Sort is the canonical sort spec the cursor was issued for
+	Sort string `json:"s,omitempty"`
+	// Values holds the sort key of the boundary row, excluding the id tiebreaker
+	Values []string `json:"v,omitempty"`
+	ID     int64    `json:"id"`
+	// Backward pages towards the start of the ordering (prev_cursor)
+	Backward bool `json:"back,omitempty"`
+}
+
+type cursorCodec struct {
+	secret []byte
+}
+
+// newCursorCodec falls back to a random secret, which keeps cursors valid only
+// for the lifetime of this process.
+func newCursorCodec(secret string) cursorCodec {
+	if secret == "" {
+		b := make([]byte, 32)
+		_, _ = rand.Read(b)
+		return cursorCodec{secret: b}
+	}
+	return cursorCodec{secret: []byte(secret)}
+}
+
+func (c cursorCodec) encode(cur Cursor) string {
+	payload, _ := json.Marshal(cur)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload))
+}
+
+func (c cursorCodec) decode(token string) (Cursor, error) {
+	enc := base64.RawURLEncoding
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var cur Cursor
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+func (c cursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,177 @@
+package article
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := newCursorCodec("secret")
+	cur := Cursor{Sort: "status,-title,id", Values: []string{"draft", "Title"}, ID: 42, Backward: true}
+	got, err := c.decode(c.encode(cur))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Sort != cur.Sort || !slices.Equal(got.Values, cur.Values) || got.ID != cur.ID || !got.Backward {
+		t.Errorf("decoded %+v, want %+v", got, cur)
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	c := newCursorCodec("secret")
+	token := c.encode(Cursor{Sort: "id", ID: 42})
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := newCursorCodec("secret").encode(Cursor{Sort: "id", ID: 1})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	cases := map[string]string{
+		"other secret":     newCursorCodec("other").encode(Cursor{Sort: "id", ID: 42}),
+		"swapped payload":  forgedPayload + "." + sig,
+		"truncated sig":    payload + "." + sig[:len(sig)-2],
+		"no signature":     payload,
+		"not base64":       "!!!." + sig,
+		"empty":            "",
+		"signed non-json":  "bm90IGpzb24." + base64.RawURLEncoding.EncodeToString(c.sign([]byte("not json"))),
+		"payload modified": strings.ToUpper(payload[:1]) + payload[1:] + "." + sig,
+	}
+	for name, tok := range cases {
+		if tok == token {
+			continue
+		}
+		if _, err := c.decode(tok); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: decode = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestKeysetConditionSQL(t *testing.T) {
+	fields := normalizeSort([]SortField{{Field: "status"}, {Field: "title", Desc: true}})
+	cond, args, err := keysetCondition(fields, Cursor{Values: []string{"draft", "B"}, ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// status is compared as a string, like ORDER BY sorts it
+	want := "((CAST(status AS CHAR) > ?) OR (CAST(status AS CHAR) = ? AND title < ?) OR (CAST(status AS CHAR) = ? AND title = ? AND id > ?))"
+	if cond != want {
+		t.Errorf("condition\n got %s\nwant %s", cond, want)
+	}
+	if fmt.Sprint(args) != "[draft draft B draft B 7]" {
+		t.Errorf("args = %v", args)
+	}
+	if got := orderByClause(fields, true); got != " ORDER BY CAST(status AS CHAR) DESC, title, id DESC" {
+		t.Errorf("reversed order = %q", got)
+	}
+
+	if _, _, err := keysetCondition(fields, Cursor{Values: []string{"draft"}, ID: 7}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor with missing values = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestKeysetPagination(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	// statuses sort as strings: draft, publish, review
+	titles := []string{"Alpha", "Bravo", "Charlie", "Alpha", "Delta", "Echo", "Bravo"}
+	for i, title := range titles {
+		a := env.create(t, title)
+		if i%3 == 0 {
+			continue
+		}
+		if _, err := env.svc.Transition(ctx, a.ID, "submit", TransitionRequest{}, "tester"); err != nil {
+			t.Fatal(err)
+		}
+		if i%3 == 2 {
+			if _, err := env.svc.Transition(ctx, a.ID, "approve", TransitionRequest{}, "tester"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	sort, _ := ParseSort("status,-title")
+	all, _, err := env.svc.List(ctx, ListParams{Limit: 100, Sort: sort})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make([]string, len(all))
+	for i, a := range all {
+		statuses[i] = a.Status
+	}
+	if len(all) != len(titles) || !slices.IsSorted(statuses) || statuses[0] != StatusDraft || statuses[len(all)-1] != StatusReview {
+		t.Fatalf("statuses = %v, want them in string order", statuses)
+	}
+
+	// forward through every page, then back again
+	var pages [][]int64
+	cursor := ""
+	for {
+		items, meta, err := env.svc.List(ctx, ListParams{Limit: 3, Sort: sort, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(items))
+		if !meta.HasNext {
+			break
+		}
+		cursor = meta.NextCursor
+	}
+	if got := slices.Concat(pages...); !slices.Equal(got, ids(all)) {
+		t.Fatalf("forward pages = %v, want %v", got, ids(all))
+	}
+
+	last, meta, err := env.svc.List(ctx, ListParams{Limit: 3, Cursor: cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := len(pages) - 2; i >= 0; i-- {
+		if meta.PrevCursor == "" {
+			t.Fatalf("page %d has no prev_cursor", i+1)
+		}
+		var items []Article
+		if items, meta, err = env.svc.List(ctx, ListParams{Limit: 3, Cursor: meta.PrevCursor}); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(items), pages[i]) {
+			t.Fatalf("backward page %d = %v, want %v", i, ids(items), pages[i])
+		}
+	}
+	if !slices.Equal(ids(last), pages[len(pages)-1]) {
+		t.Errorf("last page = %v, want %v", ids(last), pages[len(pages)-1])
+	}
+
+	// a cursor is bound to the sort it was issued for
+	other, _ := ParseSort("title")
+	if _, _, err := env.svc.List(ctx, ListParams{Limit: 3, Sort: other, Cursor: cursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor with another sort = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func ids(items []Article) []int64 {
+	res := make([]int64, len(items))
+	for i, a := range items {
+		res[i] = a.ID
+	}
+	return res
+}
+
+func TestListRejectsInvalidQueryWith400(t *testing.T) {
+	env := newTestEnv(t)
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleReader})
+	for _, query := range []string{"sort=-views", "status=archived", "fields=views"} {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/articles?"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", query, res.StatusCode)
+		}
+	}
+}
@@ -1,17 +1,97 @@
 package article
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/diff"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
 type CreateArticleRequest struct {
-	Title    string `json:"title" validate:"required,min=20"`
-	Content  string `json:"content" validate:"required,min=200"`
-	Category string `json:"category" validate:"required,min=3"`
-	Status   string `json:"status" validate:"required,oneof=publish draft thrash"`
+	Title    string   `json:"title" validate:"required,min=20"`
+	Content  string   `json:"content" validate:"required,min=200"`
+	Category string   `json:"category" validate:"required,min=3"`
+	Status   string   `json:"status" validate:"required,oneof=publish draft thrash scheduled review"`
+	Tags     []string `json:"tags" validate:"omitempty,max=10,dive,min=2,max=50"`
+	// ContentFormat defaults to plain
+	ContentFormat string `json:"content_format" validate:"omitempty,oneof=plain markdown html"`
+	// PublishAt is required for, and only allowed with, status scheduled
+	PublishAt *time.Time `json:"publish_at"`
+	// ExternalID is the id in the system the article comes from, unique when set
+	ExternalID string `json:"external_id" validate:"omitempty,max=191"`
 }
 
 type UpdateArticleRequest struct {
-	Title    string `json:"title" validate:"omitempty,min=20"`
-	Content  string `json:"content" validate:"omitempty,min=200"`
-	Category string `json:"category" validate:"omitempty,min=3"`
-	Status   string `json:"status" validate:"omitempty,oneof=publish draft thrash"`
+	Title   string `json:"title" validate:"omitempty,min=20"`
+	Content string `json:"content" validate:"omitempty,min=200"`
+	// ContentFormat empty keeps the current format
+	ContentFormat string `json:"content_format" validate:"omitempty,oneof=plain markdown html"`
+	Category      string `json:"category" validate:"omitempty,min=3"`
+	// Status changes follow the Workflow; trash and restore have their own endpoints
+	Status string `json:"status" validate:"omitempty,oneof=publish draft thrash scheduled review"`
+	// Tags nil keeps the current tags, an empty list removes them
+	Tags []string `json:"tags" validate:"omitempty,max=10,dive,min=2,max=50"`
+	// PublishAt reschedules a scheduled article
+	PublishAt *time.Time `json:"publish_at"`
+	// Comment is recorded with a status change; some transitions require it
+	Comment string `json:"comment" validate:"max=2000"`
+	// ExternalID empty keeps the current one
+	ExternalID string `json:"external_id" validate:"omitempty,max=191"`
+}
+
+// TransitionRequest is the body of POST /articles/:id/transitions/:action
+type TransitionRequest struct {
+	Comment string `json:"comment" validate:"max=2000"`
+	// PublishAt is required by the schedule action
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// Bulk operation kinds
+const (
+	BulkCreate    = "create"
+	BulkUpdate    = "update"
+	BulkDelete    = "delete"
+	BulkSetStatus = "set_status"
+)
+
+// MaxBulkOperations limits the operations of one bulk request
+const MaxBulkOperations = 100
+
+// BulkRequest is the body of POST /articles/bulk
+type BulkRequest struct {
+	// Atomic runs every operation in one transaction: all are applied or none.
+	// Otherwise each operation is applied on its own and reported per item.
+	Atomic     bool            `json:"atomic"`
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation is one item of a bulk request. ID is required except for create.
+type BulkOperation struct {
+	Op string `json:"op" validate:"required,oneof=create update delete set_status"`
+	ID int64  `json:"id" validate:"omitempty,min=1"`
+	// Data is the CreateArticleRequest or UpdateArticleRequest of create and update
+	Data json.RawMessage `json:"data"`
+	// Version guards an update like If-Match, 0 skips the check
+	Version int64 `json:"version" validate:"omitempty,min=1"`
+	// Permanent deletes instead of moving to trash
+	Permanent bool `json:"permanent"`
+	// Status is the target of set_status, reached through the Workflow
+	Status    string     `json:"status" validate:"omitempty,oneof=publish draft thrash scheduled review"`
+	Comment   string     `json:"comment" validate:"max=2000"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// BulkResult is the outcome of one bulk operation
+type BulkResult struct {
+	Index   int                       `json:"index"`
+	Op      string                    `json:"op"`
+	ID      int64                     `json:"id,omitempty"`
+	Success bool                      `json:"success"`
+	Status  int                       `json:"status"`
+	Data    *Article                  `json:"data,omitempty"`
+	Error   string                    `json:"error,omitempty"`
+	Errors  []validatorpkg.FieldError `json:"errors,omitempty"`
 }
 
 type ListResponse struct {
@@ -26,9 +106,81 @@ type PaginationMeta struct {
 	HasNext bool  `json:"has_next"`
 }
 
-// title (substring match), category (exact), dan status (exact).
+// ListQuery is what the repository needs to fetch one page of articles.
+type ListQuery struct {
+	Filter ListFilter
+	// Sort is normalized, i.e. always ends with id
+	Sort  []SortField
+	Limit int
+	// Offset is ignored when Cursor is set
+	Offset int
+	// Cursor switches to keyset pagination. Rows come back in scan order,
+	// i.e. descending for a backward cursor.
+	Cursor *Cursor
+	// Fields limits the columns read, nil reads every column. It must
+	// include the Sort fields, which the cursors are built from.
+	Fields []string
+}
+
+// ListParams are the list options accepted from the HTTP layer.
+type ListParams struct {
+	Limit        int
+	Page         int
+	Cursor       string
+	Sort         []SortField
+	IncludeTotal bool
+	Filter       ListFilter
+	// Fields is the projection of ParseFields, nil returns every field
+	Fields []string
+}
+
+// SearchQuery is a full-text search over title and content, combined with ListFilter.
+type SearchQuery struct {
+	Query  string
+	Mode   string
+	Filter ListFilter
+	Limit  int
+	Offset int
+}
+
+type SearchHit struct {
+	Article
+	Score      float64    `json:"score"`
+	Highlights Highlights `json:"highlights"`
+}
+
+// Highlights contain HTML-escaped text with matched terms wrapped in <mark>.
+type Highlights struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// title/content (substring match), category dan status (exact, multi-value,
+// bisa dinegasikan), serta rentang tanggal. Field kosong berarti tidak difilter.
 type ListFilter struct {
-	Title    string `json:"title"`
-	Category string `json:"category"`
-	Status   string `json:"status"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	Categories    []string `json:"categories"`
+	NotCategories []string `json:"not_categories"`
+	Statuses      []string `json:"statuses"`
+	NotStatuses   []string `json:"not_statuses"`
+	// Tags are tag slugs; TagMatch decides whether any or all must be present
+	Tags         []string   `json:"tags"`
+	TagMatch     string     `json:"tag_match"`
+	CreatedFrom  *time.Time `json:"created_from"`
+	CreatedTo    *time.Time `json:"created_to"`
+	UpdatedSince *time.Time `json:"updated_since"`
+}
+
+// RevisionDiff compares two revisions: changed scalar fields plus a line-level content diff.
+type RevisionDiff struct {
+	From    int                    `json:"from"`
+	To      int                    `json:"to"`
+	Fields  map[string]FieldChange `json:"fields"`
+	Content []diff.Line            `json:"content"`
+}
+
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
 }
@@ -0,0 +1,22 @@
+package article
+
+import "errors"
+
+var (
+	ErrAlreadyTrashed = errors.New("article sudah berada di trash")
+	ErrNotTrashed     = errors.New("article tidak berada di trash")
+	// ErrVersionMismatch means the article changed since the version the caller based its write on
+	ErrVersionMismatch = errors.New("article sudah diubah oleh request lain, ambil versi terbaru")
+	ErrSlugConflict    = errors.New("slug sudah dipakai article lain")
+	// ErrExternalIDConflict means another article was already imported with the same external_id
+	ErrExternalIDConflict = errors.New("external_id sudah dipakai article lain")
+	ErrUnknownCategory    = errors.New("category tidak terdaftar")
+	// ErrInvalidSchedule means publish_at is missing, in the past or sent without status scheduled
+	ErrInvalidSchedule = errors.New("status scheduled membutuhkan publish_at di masa depan, dan publish_at hanya untuk status scheduled")
+	// ErrCommentRequired means the transition needs a reviewer comment
+	ErrCommentRequired = errors.New("transisi ini membutuhkan comment")
+	// ErrInvalidEntryStatus means a new article was sent with a status only reachable through the workflow
+	ErrInvalidEntryStatus = errors.New("article baru hanya bisa dibuat dengan status draft, publish atau scheduled")
+	// ErrInvalidSearchQuery means MySQL could not parse a boolean mode search query
+	ErrInvalidSearchQuery = errors.New("query pencarian boolean tidak valid: periksa operator (+, -, *) dan tanda kutip yang belum ditutup")
+)
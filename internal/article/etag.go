@@ -0,0 +1,39 @@
+package article
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var errInvalidIfMatch = errors.New("header If-Match tidak valid")
+
+// etag derives a strong entity tag from the article version
+func etag(a Article) string {
+	return `"` + strconv.FormatInt(a.Version, 10) + `"`
+}
+
+// versionFromIfMatch extracts the version asserted by an If-Match header.
+// It returns 0 when the header is absent or "*" (any version).
+func versionFromIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || v <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return v, nil
+}
+
+// noneMatch reports whether an If-None-Match header matches tag (weak comparison)
+func noneMatch(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package article
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
+)
+
+// Outbox queues article events for webhook delivery. AddEvent must join the
+// transaction of Repository.WithinTx carried by ctx.
+type Outbox interface {
+	AddEvent(ctx context.Context, e webhook.Event) (webhook.Event, error)
+}
+
+// eventTypes lists the webhook events of a change from before to after; a
+// nil before is a create, a nil after a delete. For receivers moving an
+// article to the trash deletes it and restoring creates it again, so
+// permanently deleting a trashed article has no event left to send.
+func eventTypes(before, after *Article) []string {
+	switch {
+	case before == nil || (before.Status == StatusThrash && after != nil && after.Status != StatusThrash):
+		if after.Status == StatusPublish {
+			return []string{webhook.EventArticleCreated, webhook.EventArticlePublished}
+		}
+		return []string{webhook.EventArticleCreated}
+	case before.Status == StatusThrash:
+		return nil
+	case after == nil || after.Status == StatusThrash:
+		return []string{webhook.EventArticleDeleted}
+	case after.Status == StatusPublish && before.Status != StatusPublish:
+		return []string{webhook.EventArticleUpdated, webhook.EventArticlePublished}
+	}
+	return []string{webhook.EventArticleUpdated}
+}
+
+// enqueueEvents writes the webhook events of a change to the outbox. The
+// payload is the article after the change, or before it for deletes.
+func (s *Service) enqueueEvents(ctx context.Context, id int64, before, after *Article) error {
+	a := after
+	if a == nil {
+		a = before
+	}
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	for _, t := range eventTypes(before, after) {
+		if _, err := s.outbox.AddEvent(ctx, webhook.Event{Type: t, ArticleID: id, Payload: payload}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package article
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats; ndjson is an alias of jsonl
+const (
+	ExportCSV    = "csv"
+	ExportJSONL  = "jsonl"
+	ExportNDJSON = "ndjson"
+)
+
+var ErrUnknownExportFormat = errors.New("format export tidak dikenal: pilih csv | jsonl | ndjson")
+
+func IsValidExportFormat(format string) bool {
+	return format == ExportCSV || format == ExportJSONL || format == ExportNDJSON
+}
+
+// exportColumns is the CSV header; tags are joined with "|"
+var exportColumns = []string{"id", "external_id", "title", "slug", "content", "content_format", "category", "status", "tags", "author_id", "publish_at", "version", "created_at", "updated_at"}
+
+// ExportWriter encodes articles one by one in an export format
+type ExportWriter interface {
+	Write(a Article) error
+	// Flush writes any buffered data and reports earlier write errors
+	Flush() error
+}
+
+// NewExportWriter returns the writer of format, writing the CSV header right away
+func NewExportWriter(w io.Writer, format string) (ExportWriter, error) {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return csvExportWriter{cw}, nil
+	case ExportJSONL, ExportNDJSON:
+		return jsonlExportWriter{json.NewEncoder(w)}, nil
+	}
+	return nil, ErrUnknownExportFormat
+}
+
+// ExportContentType is the Content-Type of format
+func ExportContentType(format string) string {
+	if format == ExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e csvExportWriter) Write(a Article) error {
+	author := ""
+	if a.AuthorID != nil {
+		author = strconv.FormatInt(*a.AuthorID, 10)
+	}
+	publishAt := ""
+	if a.PublishAt != nil {
+		publishAt = a.PublishAt.UTC().Format(time.RFC3339)
+	}
+	return e.w.Write([]string{
+		strconv.FormatInt(a.ID, 10),
+		csvCell(a.ExternalID),
+		csvCell(a.Title),
+		a.Slug,
+		csvCell(a.Content),
+		a.ContentFormat,
+		csvCell(a.Category),
+		a.Status,
+		csvCell(strings.Join(a.Tags, "|")),
+		author,
+		publishAt,
+		strconv.FormatInt(a.Version, 10),
+		a.CreatedAt.UTC().Format(time.RFC3339),
+		a.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// csvFormulaPrefixes make spreadsheet tools read a cell as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// formulaLike reports whether v, after any leading quotes, starts like a formula
+func formulaLike(v string) bool {
+	v = strings.TrimLeft(v, "'")
+	return v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0]))
+}
+
+// csvCell prefixes a user supplied value that a spreadsheet would evaluate
+// with a quote. Values already starting with quotes get one more, so
+// uncsvCell restores every value exactly when the file is imported again.
+func csvCell(v string) string {
+	if formulaLike(v) {
+		return "'" + v
+	}
+	return v
+}
+
+// uncsvCell reverses csvCell
+func uncsvCell(v string) string {
+	if formulaLike(v) {
+		return strings.TrimPrefix(v, "'")
+	}
+	return v
+}
+
+func (e csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlExportWriter struct {
+	enc *json.Encoder
+}
+
+// Write encodes a as one line; Encode terminates every value with a newline
+func (e jsonlExportWriter) Write(a Article) error {
+	return e.enc.Encode(a)
+}
+
+func (e jsonlExportWriter) Flush() error {
+	return nil
+}
+
+// Export writes every article matching filter to w in format and returns
+// how many were written. Rows are streamed, so memory use does not grow with
+// the size of the export.
+func (s *Service) Export(ctx context.Context, filter ListFilter, format string, w io.Writer) (int, error) {
+	ew, err := NewExportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	err = s.repo.Export(ctx, filter, func(a Article) error {
+		n++
+		return ew.Write(a)
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, ew.Flush()
+}
@@ -0,0 +1,78 @@
+package article
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// articleFields lists the JSON keys of Article, the names accepted by
+// ?fields=; articleFieldIndex maps each key to its struct field index
+var articleFields, articleFieldIndex = jsonFields(reflect.TypeOf(Article{}))
+
+// jsonFields returns the JSON keys of the fields of struct type t in
+// declaration order, and the index of the field of every key
+func jsonFields(t reflect.Type) ([]string, map[string]int) {
+	fields := make([]string, 0, t.NumField())
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+			index[name] = i
+		}
+	}
+	return fields, index
+}
+
+func isArticleField(field string) bool {
+	_, ok := articleFieldIndex[field]
+	return ok
+}
+
+// ParseFields parses an "id,title,status" style projection. An empty spec
+// returns nil, selecting every field; unknown fields are reported per item.
+func ParseFields(spec string) ([]string, []validatorpkg.FieldError) {
+	var fields []string
+	errs := make([]validatorpkg.FieldError, 0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		f := strings.ToLower(strings.TrimSpace(part))
+		switch {
+		case f == "" || seen[f]:
+			continue
+		case !isArticleField(f):
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "fields",
+				Message: fmt.Sprintf("field %q tidak dikenal, pilih dari: %s", f, strings.Join(articleFields, ", ")),
+				Tag:     "oneof",
+				Param:   f,
+			})
+		default:
+			seen[f] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, errs
+}
+
+// projectArticle returns the fields of a keyed by their JSON name. Unlike
+// the JSON of Article, empty optional fields are kept, as null when unset.
+func projectArticle(a Article, fields []string) map[string]interface{} {
+	v := reflect.ValueOf(a)
+	res := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		res[f] = v.Field(articleFieldIndex[f]).Interface()
+	}
+	return res
+}
+
+func projectArticles(items []Article, fields []string) []map[string]interface{} {
+	res := make([]map[string]interface{}, len(items))
+	for i, a := range items {
+		res[i] = projectArticle(a, fields)
+	}
+	return res
+}
@@ -1,31 +1,88 @@
 package article
 
 import (
+	"bufio"
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
 	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
 )
 
 type Handler struct {
 	svc       *Service
+	policy    *Policy
 	validator *validatorpkg.Validator
+	importer  *Importer
 }
 
-func NewHandler(svc *Service, validator *validatorpkg.Validator) *Handler {
-	return &Handler{svc: svc, validator: validator}
+func NewHandler(svc *Service, policy *Policy, validator *validatorpkg.Validator) *Handler {
+	return &Handler{svc: svc, policy: policy, validator: validator, importer: NewImporter(svc, validator)}
 }
 
-func (h *Handler) Register(r fiber.Router) {
-	r.Post("/", h.create)
+// Register mounts the article endpoints. Mutating routes run requireUser first.
+func (h *Handler) Register(r fiber.Router, requireUser fiber.Handler) {
+	r.Post("/", requireUser, h.create)
+	r.Post("/bulk", requireUser, h.bulk)
+	r.Post("/import", requireUser, h.importArticles)
 	r.Get("/", h.list)
+	r.Get("/search", h.search)
+	r.Get("/stream", h.stream)
+	r.Get("/export", requireUser, h.export)
+	r.Get("/slug/:slug", h.getBySlug)
 	r.Get("/:id", h.getByID)
-	r.Put("/:id", h.update)
-	r.Delete("/:id", h.delete)
+	r.Put("/:id", requireUser, h.update)
+	r.Delete("/:id", requireUser, h.delete)
+	r.Post("/:id/restore", requireUser, h.restore)
+	r.Get("/:id/transitions", h.listTransitions)
+	r.Post("/:id/transitions/:action", requireUser, h.transition)
+	r.Get("/:id/revisions", h.listRevisions)
+	r.Get("/:id/revisions/diff", h.diffRevisions)
+	r.Get("/:id/revisions/:rev", h.getRevision)
+	r.Post("/:id/revisions/:rev/restore", requireUser, h.restoreRevision)
+}
+
+// RegisterTags mounts the tag endpoints, e.g. on /tags
+func (h *Handler) RegisterTags(r fiber.Router) {
+	r.Get("/", h.listTags)
+}
+
+func (h *Handler) listTags(c *fiber.Ctx) error {
+	tags, err := h.svc.ListTags(c.Context())
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, tags, "tags retrieved successfully")
+}
+
+// entryStatusErrors rejects a valid status a new article cannot start in;
+// unknown statuses are already reported by the validator
+func entryStatusErrors(field, status string) []validatorpkg.FieldError {
+	if !IsValidStatus(status) || isEntryStatus(status) {
+		return nil
+	}
+	return []validatorpkg.FieldError{{
+		Field:   field,
+		Message: fmt.Sprintf("status article baru: pilih %s, status lain dicapai lewat workflow", strings.Join(EntryStatuses, " | ")),
+		Tag:     "oneof",
+		Param:   status,
+	}}
 }
 
 func (h *Handler) create(c *fiber.Ctx) error {
@@ -34,13 +91,28 @@ func (h *Handler) create(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
 	}
 	errors, _ := h.validator.ValidateStructDetailed(req)
+	errors = append(errors, entryStatusErrors("status", req.Status)...)
+	errors = append(errors, tagErrors(req.Tags)...)
 	if len(errors) > 0 {
 		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
 	}
-	art, err := h.svc.Create(c.Context(), req)
+	p, _ := auth.PrincipalFrom(c)
+	// a new article is checked as the caller's draft moving to the requested status
+	draft := Article{AuthorID: &p.UserID, Status: StatusDraft}
+	if err := h.authorizeWrite(p, ActionCreate, draft, req.Status); err != nil {
+		return response.Fail(c, fiber.StatusForbidden, err.Error())
+	}
+	art, err := h.svc.Create(mutationContext(c), req, p.UserID)
 	if err != nil {
+		switch err {
+		case ErrSlugConflict, ErrExternalIDConflict:
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case ErrUnknownCategory, ErrInvalidSchedule, ErrCommentRequired, ErrInvalidEntryStatus:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
+		}
 		return response.Fail(c, fiber.StatusBadRequest, err.Error())
 	}
+	c.Set(fiber.HeaderETag, etag(art))
 	return response.Success(c, fiber.StatusCreated, art, "article created successfully")
 }
 
@@ -48,41 +120,414 @@ func (h *Handler) list(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 
-	// Filters
-	var filter ListFilter
-	if st := strings.ToLower(c.Query("status")); st != "" {
-		switch st {
-		case "publish", "draft", "thrash":
-			filter.Status = st
-		default:
-			return response.Fail(c, fiber.StatusBadRequest, "status filter invalid: pilih publish | draft | thrash")
-		}
+	filter, filterErrs := parseListFilter(c)
+	if len(filterErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, filterErrs)
 	}
-	if cat := strings.TrimSpace(c.Query("category")); cat != "" {
-		filter.Category = cat
+	sortFields, sortErrs := ParseSort(c.Query("sort"))
+	if len(sortErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, sortErrs)
 	}
-	if title := strings.ToLower(c.Query("title")); title != "" {
-		filter.Title = title
+	fields, fieldErrs := ParseFields(c.Query("fields"))
+	if len(fieldErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, fieldErrs)
 	}
 
-	items, meta, err := h.svc.List(c.Context(), limit, page, filter)
+	items, meta, err := h.svc.List(c.Context(), ListParams{
+		Limit:        limit,
+		Page:         page,
+		Cursor:       c.Query("cursor"),
+		Sort:         sortFields,
+		IncludeTotal: c.QueryBool("include_total", true),
+		Filter:       filter,
+		Fields:       fields,
+	})
 	if err != nil {
+		if err == ErrInvalidCursor {
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
 		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
 	}
 
+	if fields != nil {
+		return response.Success(c, fiber.StatusOK, map[string]interface{}{
+			"items": projectArticles(items, fields),
+			"meta":  meta,
+		}, "articles retrieved successfully")
+	}
 	return response.Success(c, fiber.StatusOK, map[string]interface{}{
 		"items": items,
 		"meta":  meta,
 	}, "articles retrieved successfully")
 }
 
+func (h *Handler) search(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "q wajib diisi")
+	}
+	mode := strings.ToLower(c.Query("mode", SearchModeNatural))
+	if mode != SearchModeNatural && mode != SearchModeBoolean {
+		return response.Fail(c, fiber.StatusBadRequest, "mode invalid: pilih natural | boolean")
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+
+	filter, filterErrs := parseListFilter(c)
+	if len(filterErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, filterErrs)
+	}
+
+	hits, meta, err := h.svc.Search(c.Context(), SearchQuery{Query: q, Mode: mode, Filter: filter, Limit: limit}, page)
+	if err != nil {
+		if err == ErrInvalidSearchQuery {
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, map[string]interface{}{
+		"items": hits,
+		"meta":  meta,
+	}, "articles found successfully")
+}
+
+// streamHeartbeat is how often an idle stream sends a comment, keeping
+// proxies from closing it and noticing clients that went away
+const streamHeartbeat = 15 * time.Second
+
+// stream sends committed article changes as Server-Sent Events, optionally
+// filtered by category and status (comma separated). A reconnecting client
+// resumes after its Last-Event-ID header (or last_event_id query param); when
+// those events are no longer buffered a "reset" event tells it to reload.
+func (h *Handler) stream(c *fiber.Ctx) error {
+	errs := make([]validatorpkg.FieldError, 0)
+	filter := StreamFilter{Categories: categorySlugs(c.Query("category"))}
+	filter.Statuses = splitQueryList(strings.ToLower(c.Query("status")))
+	for _, st := range filter.Statuses {
+		if !IsValidStatus(st) {
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "status",
+				Message: fmt.Sprintf("status filter invalid: pilih %s", strings.Join(Statuses, " | ")),
+				Tag:     "oneof",
+				Param:   st,
+			})
+		}
+	}
+	var lastID uint64
+	if v := c.Get("Last-Event-ID", c.Query("last_event_id")); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "last_event_id",
+				Message: "last_event_id harus berupa angka",
+				Tag:     "numeric",
+				Param:   v,
+			})
+		}
+		lastID = id
+	}
+	if len(errs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, errs)
+	}
+
+	broker := h.svc.Changes()
+	sub, backlog, complete := broker.Subscribe(filter, lastID)
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer broker.Unsubscribe(sub)
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if !complete {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, e := range backlog {
+			if err := writeChangeEvent(w, e); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case e, ok := <-sub.C:
+				if !ok {
+					// dropped as too slow or shutting down, the client reconnects
+					return
+				}
+				if err := writeChangeEvent(w, e); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			// a failed flush means the client disconnected
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeChangeEvent(w *bufio.Writer, e ChangeEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+// export streams every article matching the list filters as a csv or jsonl
+// (ndjson) download. The status is already sent when rows are written, so a
+// failure halfway is only logged and ends the file early.
+func (h *Handler) export(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", ExportCSV))
+	if !IsValidExportFormat(format) {
+		return response.Fail(c, fiber.StatusBadRequest, ErrUnknownExportFormat.Error())
+	}
+	filter, filterErrs := parseListFilter(c)
+	if len(filterErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, filterErrs)
+	}
+
+	filename := fmt.Sprintf("articles-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentType, ExportContentType(format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	requestID := utils.CopyString(c.GetRespHeader(fiber.HeaderXRequestID))
+	// the fasthttp request context may be reused while the writer still runs
+	// after a disconnect, so only its shutdown signal is carried over
+	shutdown := c.Context().Done()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-shutdown:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		n, err := h.svc.Export(ctx, filter, format, w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Log.WithError(err).WithFields(map[string]interface{}{"request_id": requestID, "written": n}).Error("export articles failed")
+		}
+	})
+	return nil
+}
+
+// importArticles upserts the articles of the multipart "file" field. The
+// format defaults to the file extension; report=csv answers with the failed
+// rows as a downloadable CSV instead of the JSON report.
+func (h *Handler) importArticles(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "file wajib diisi (multipart field file)")
+	}
+	format := strings.ToLower(c.Query("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fh.Filename)), ".")
+	}
+	if !IsValidExportFormat(format) {
+		return response.Fail(c, fiber.StatusBadRequest, ErrUnknownExportFormat.Error())
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	defer f.Close()
+
+	p, _ := auth.PrincipalFrom(c)
+	rep, err := h.importer.Import(mutationContext(c), f, ImportOptions{
+		Format:    format,
+		Key:       strings.ToLower(c.Query("key", ImportKeyExternalID)),
+		DryRun:    c.QueryBool("dry_run"),
+		BatchSize: c.QueryInt("batch_size", DefaultImportBatchSize),
+		AuthorID:  p.UserID,
+		Authorize: func(action Action, curr Article, status string) error {
+			return h.authorizeWrite(p, action, curr, status)
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownImportKey):
+			return response.Fail(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, ErrInvalidImportFile):
+			// rows before the broken part are imported all the same
+			return response.Fail(c, fiber.StatusUnprocessableEntity, fmt.Sprintf("%s (%d baris sebelumnya sudah diproses: %d dibuat, %d diubah, %d tidak berubah, %d gagal)",
+				err, rep.Total, rep.Created, rep.Updated, rep.Unchanged, rep.Failed))
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	if c.Query("report") == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="import-errors-%s.csv"`, time.Now().UTC().Format("20060102-150405")))
+		return WriteImportErrors(c, rep)
+	}
+	msg := "articles imported"
+	if rep.DryRun {
+		msg = "import dry run finished, nothing was written"
+	}
+	return response.Success(c, fiber.StatusOK, rep, msg)
+}
+
+// parseListFilter reads the filter query params shared by list, search and export
+func parseListFilter(c *fiber.Ctx) (ListFilter, []validatorpkg.FieldError) {
+	return ParseListFilter(func(key string) string { return c.Query(key) })
+}
+
+// ParseListFilter builds a ListFilter from the values get returns for the keys
+// title, content, status / status!, category / category!, tag + tag_match (comma separated)
+// and created_from, created_to, updated_since (RFC3339 or YYYY-MM-DD).
+func ParseListFilter(get func(key string) string) (ListFilter, []validatorpkg.FieldError) {
+	errs := make([]validatorpkg.FieldError, 0)
+	filter := ListFilter{
+		Title:         strings.ToLower(strings.TrimSpace(get("title"))),
+		Content:       strings.ToLower(strings.TrimSpace(get("content"))),
+		Categories:    categorySlugs(get("category")),
+		NotCategories: categorySlugs(get("category!")),
+	}
+
+	for _, key := range []string{"status", "status!"} {
+		values := splitQueryList(strings.ToLower(get(key)))
+		for _, st := range values {
+			if !IsValidStatus(st) {
+				errs = append(errs, validatorpkg.FieldError{
+					Field:   key,
+					Message: fmt.Sprintf("status filter invalid: pilih %s", strings.Join(Statuses, " | ")),
+					Tag:     "oneof",
+					Param:   st,
+				})
+			}
+		}
+		if key == "status" {
+			filter.Statuses = values
+		} else {
+			filter.NotStatuses = values
+		}
+	}
+
+	for _, t := range normalizeTags(splitQueryList(get("tag"))) {
+		filter.Tags = append(filter.Tags, t.Slug)
+	}
+	filter.TagMatch = strings.ToLower(get("tag_match"))
+	if filter.TagMatch == "" {
+		filter.TagMatch = TagMatchAny
+	}
+	if filter.TagMatch != TagMatchAny && filter.TagMatch != TagMatchAll {
+		errs = append(errs, validatorpkg.FieldError{
+			Field:   "tag_match",
+			Message: "tag_match invalid: pilih any | all",
+			Tag:     "oneof",
+			Param:   filter.TagMatch,
+		})
+	}
+
+	var err *validatorpkg.FieldError
+	if filter.CreatedFrom, err = parseTimeValue("created_from", get("created_from"), false); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.CreatedTo, err = parseTimeValue("created_to", get("created_to"), true); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.UpdatedSince, err = parseTimeValue("updated_since", get("updated_since"), false); err != nil {
+		errs = append(errs, *err)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		errs = append(errs, validatorpkg.FieldError{
+			Field:   "created_from",
+			Message: "created_from tidak boleh setelah created_to",
+			Tag:     "ltefield",
+			Param:   "created_to",
+		})
+	}
+
+	return filter, errs
+}
+
+// categorySlugs turns a comma separated list of category names or slugs into slugs
+func categorySlugs(v string) []string {
+	res := make([]string, 0)
+	for _, name := range splitQueryList(v) {
+		if s := slug.Make(name); s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// splitQueryList splits a comma separated query value, dropping empty items
+func splitQueryList(v string) []string {
+	res := make([]string, 0)
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			res = append(res, part)
+		}
+	}
+	return res
+}
+
+// parseTimeValue accepts RFC3339 or a plain date. A plain date used as an
+// upper bound covers the whole day.
+func parseTimeValue(key, v string, endOfDay bool) (*time.Time, *validatorpkg.FieldError) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+	if err != nil {
+		return nil, &validatorpkg.FieldError{
+			Field:   key,
+			Message: fmt.Sprintf("%s harus berformat RFC3339 atau YYYY-MM-DD", key),
+			Tag:     "datetime",
+			Param:   v,
+		}
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return &t, nil
+}
+
 func (h *Handler) getByID(c *fiber.Ctx) error {
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
 		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
 	}
 
-	art, err := h.svc.GetByID(c.Context(), id)
+	renderAs := c.Query("render")
+	if renderAs != "" && renderAs != RenderHTML {
+		return response.Fail(c, fiber.StatusBadRequest, "render tidak dikenal: pilih html")
+	}
+	fields, fieldErrs := ParseFields(c.Query("fields"))
+	if len(fieldErrs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, fieldErrs)
+	}
+	read := fields
+	if fields != nil {
+		// the ETag needs the version, rendering the content and its format
+		read = append(slices.Clone(fields), "version")
+		if renderAs == RenderHTML {
+			read = append(read, "content", "content_format")
+		}
+	}
+
+	art, err := h.svc.GetByIDFields(c.Context(), id, read)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
@@ -90,6 +535,62 @@ func (h *Handler) getByID(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
 	}
 
+	tag := etag(art)
+	c.Set(fiber.HeaderETag, tag)
+	if noneMatch(c.Get(fiber.HeaderIfNoneMatch), tag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	var rendered RenderedArticle
+	if renderAs == RenderHTML {
+		if rendered, err = h.svc.Render(art); err != nil {
+			return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+		}
+	}
+	switch {
+	case fields != nil:
+		data := projectArticle(art, fields)
+		if renderAs == RenderHTML {
+			data["content_html"], data["toc"] = rendered.ContentHTML, rendered.TOC
+		}
+		return response.Success(c, fiber.StatusOK, data, "article retrieved successfully")
+	case renderAs == RenderHTML:
+		return response.Success(c, fiber.StatusOK, rendered, "article retrieved successfully")
+	}
+	return response.Success(c, fiber.StatusOK, art, "article retrieved successfully")
+}
+
+func (h *Handler) getBySlug(c *fiber.Ctx) error {
+	// route params stay percent-encoded, slugs may hold non-ASCII letters
+	sl, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+	}
+	art, moved, err := h.svc.GetBySlug(c.Context(), sl)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	if moved {
+		// old slug: point clients at the current one
+		location := strings.TrimSuffix(c.Path(), c.Params("slug")) + url.PathEscape(art.Slug)
+		c.Location(location)
+		return response.Success(c, fiber.StatusMovedPermanently, map[string]interface{}{
+			"id":       art.ID,
+			"slug":     art.Slug,
+			"location": location,
+		}, "article moved permanently")
+	}
+
+	tag := etag(art)
+	c.Set(fiber.HeaderETag, tag)
+	if noneMatch(c.Get(fiber.HeaderIfNoneMatch), tag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return response.Success(c, fiber.StatusOK, art, "article retrieved successfully")
 }
 
@@ -99,23 +600,44 @@ func (h *Handler) update(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
 	}
 
+	version, err := versionFromIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, err.Error())
+	}
+
 	var req UpdateArticleRequest
 	if errBody := c.BodyParser(&req); errBody != nil {
 		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
 	}
 
 	errors, _ := h.validator.ValidateStructDetailed(req)
+	errors = append(errors, tagErrors(req.Tags)...)
 	if len(errors) > 0 {
 		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
 	}
 
-	art, err := h.svc.Update(c.Context(), id, req)
+	if err := h.authorize(c, id, ActionUpdate, req.Status); err != nil {
+		return failAuthorize(c, err)
+	}
+
+	art, err := h.svc.Update(mutationContext(c), id, req, version, actorFromRequest(c))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if te, ok := err.(*TransitionError); ok {
+			return failTransition(c, te)
+		}
+		switch err {
+		case sql.ErrNoRows:
 			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		case ErrVersionMismatch:
+			return response.Fail(c, fiber.StatusPreconditionFailed, err.Error())
+		case ErrSlugConflict, ErrExternalIDConflict:
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case ErrUnknownCategory, ErrInvalidSchedule, ErrCommentRequired:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
 		}
 		return response.Fail(c, fiber.StatusBadRequest, err.Error())
 	}
+	c.Set(fiber.HeaderETag, etag(art))
 	return response.Success(c, fiber.StatusOK, art, "article updated successfully")
 }
 
@@ -125,13 +647,300 @@ func (h *Handler) delete(c *fiber.Ctx) error {
 		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
 	}
 
-	err = h.svc.Delete(c.Context(), id)
+	action := ActionTrash
+	if c.QueryBool("permanent") {
+		action = ActionPurge
+	}
+	if err := h.authorize(c, id, action, ""); err != nil {
+		return failAuthorize(c, err)
+	}
+
+	if action == ActionPurge {
+		err = h.svc.Delete(mutationContext(c), id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+			}
+			return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+		}
+		return response.Success(c, fiber.StatusOK, nil, "article deleted permanently")
+	}
+
+	art, err := h.svc.Trash(mutationContext(c), id, actorFromRequest(c))
+	if err != nil {
+		if te, ok := err.(*TransitionError); ok {
+			return failTransition(c, te)
+		}
+		switch err {
+		case sql.ErrNoRows:
+			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		case ErrAlreadyTrashed:
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, art, "article moved to trash")
+}
+
+func (h *Handler) restore(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+
+	if err := h.authorize(c, id, ActionRestore, ""); err != nil {
+		return failAuthorize(c, err)
+	}
+
+	art, err := h.svc.Restore(mutationContext(c), id, actorFromRequest(c))
+	if err != nil {
+		if te, ok := err.(*TransitionError); ok {
+			return failTransition(c, te)
+		}
+		switch err {
+		case sql.ErrNoRows:
+			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		case ErrNotTrashed:
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, art, "article restored successfully")
+}
+
+func (h *Handler) listTransitions(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+
+	art, err := h.svc.GetByID(c.Context(), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
 		}
 		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
 	}
+	history, err := h.svc.ListTransitions(c.Context(), id)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, map[string]interface{}{
+		"status":  art.Status,
+		"next":    NextTransitions(art.Status),
+		"history": history,
+	}, "transitions retrieved successfully")
+}
+
+func (h *Handler) transition(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	action := c.Params("action")
+
+	var req TransitionRequest
+	if len(c.Body()) > 0 {
+		if errBody := c.BodyParser(&req); errBody != nil {
+			return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+		}
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+
+	curr, err := h.svc.GetByID(c.Context(), id)
+	if err != nil {
+		return failAuthorize(c, err)
+	}
+	// an action not allowed from the current status is rejected by the service
+	if t, ok := FindTransition(curr.Status, action); ok {
+		p, _ := auth.PrincipalFrom(c)
+		if err := h.authorizeWrite(p, transitionAction(t), curr, t.To); err != nil {
+			return failAuthorize(c, err)
+		}
+	}
+
+	art, err := h.svc.Transition(mutationContext(c), id, action, req, actorFromRequest(c))
+	if err != nil {
+		if te, ok := err.(*TransitionError); ok {
+			return failTransition(c, te)
+		}
+		switch err {
+		case sql.ErrNoRows:
+			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		case ErrVersionMismatch, ErrAlreadyTrashed, ErrNotTrashed:
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		case ErrInvalidSchedule, ErrCommentRequired:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	c.Set(fiber.HeaderETag, etag(art))
+	return response.Success(c, fiber.StatusOK, art, "article transitioned successfully")
+}
+
+func (h *Handler) listRevisions(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
 
-	return response.Success(c, fiber.StatusOK, nil, "article deleted successfully")
+	revs, err := h.svc.ListRevisions(c.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, revs, "revisions retrieved successfully")
+}
+
+func (h *Handler) getRevision(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	rev, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "rev harus integer")
+	}
+
+	revision, err := h.svc.GetRevision(c.Context(), id, rev)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "revision tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, revision, "revision retrieved successfully")
+}
+
+func (h *Handler) diffRevisions(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "from dan to harus integer")
+	}
+
+	d, err := h.svc.DiffRevisions(c.Context(), id, from, to)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "revision tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, d, "revision diff retrieved successfully")
+}
+
+func (h *Handler) restoreRevision(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	rev, err := strconv.Atoi(c.Params("rev"))
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "rev harus integer")
+	}
+
+	revision, err := h.svc.GetRevision(c.Context(), id, rev)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "revision tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if err := h.authorize(c, id, ActionUpdate, revision.Status); err != nil {
+		return failAuthorize(c, err)
+	}
+
+	art, err := h.svc.RestoreRevision(mutationContext(c), id, rev, actorFromRequest(c))
+	if err != nil {
+		if te, ok := err.(*TransitionError); ok {
+			return failTransition(c, te)
+		}
+		switch err {
+		case sql.ErrNoRows:
+			return response.Fail(c, fiber.StatusNotFound, "revision tidak ditemukan")
+		case ErrUnknownCategory, ErrInvalidSchedule:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, art, "article restored to revision successfully")
+}
+
+// authorize loads article id and checks that the caller may perform action on
+// it, including the extra action needed when the write changes its status.
+func (h *Handler) authorize(c *fiber.Ctx, id int64, action Action, status string) error {
+	curr, err := h.svc.GetByID(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	p, _ := auth.PrincipalFrom(c)
+	return h.authorizeWrite(p, action, curr, status)
+}
+
+func (h *Handler) authorizeWrite(p auth.Principal, action Action, curr Article, status string) error {
+	if status != "" && status != curr.Status {
+		if extra, ok := statusAction(status); ok {
+			if err := h.policy.Authorize(p, extra, curr); err != nil {
+				return err
+			}
+		}
+	}
+	return h.policy.Authorize(p, action, curr)
+}
+
+// failAuthorize responds to an error returned by authorize
+func failAuthorize(c *fiber.Ctx, err error) error {
+	switch {
+	case err == sql.ErrNoRows:
+		return response.Fail(c, fiber.StatusNotFound, "article tidak ditemukan")
+	case errors.Is(err, ErrForbidden):
+		return response.Fail(c, fiber.StatusForbidden, err.Error())
+	}
+	return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+}
+
+// failTransition responds 409 with the transitions allowed from the current status
+func failTransition(c *fiber.Ctx, err *TransitionError) error {
+	allowed := make([]interface{}, len(err.Allowed))
+	for i, t := range err.Allowed {
+		allowed[i] = t
+	}
+	return response.FailWithErrors(c, fiber.StatusConflict, err.Error(), allowed)
+}
+
+// mutationContext carries who performs a write and the request it came from,
+// for the audit log. Header values are copied since fasthttp reuses their buffers.
+func mutationContext(c *fiber.Ctx) context.Context {
+	return audit.WithSource(c.Context(), audit.Source{
+		Actor:     actorFromRequest(c),
+		RequestID: utils.CopyString(c.GetRespHeader(fiber.HeaderXRequestID)),
+		IP:        utils.CopyString(c.IP()),
+	})
+}
+
+// actorFromRequest identifies who performs a mutation, taken from the authenticated user
+func actorFromRequest(c *fiber.Ctx) string {
+	if p, ok := auth.PrincipalFrom(c); ok {
+		if p.APIKeyID != 0 {
+			return "apikey:" + p.Name
+		}
+		return p.Email
+	}
+	return "anonymous"
 }
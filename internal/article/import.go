@@ -0,0 +1,517 @@
+package article
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// Import keys: the field matching an import row to an existing article
+const (
+	ImportKeyExternalID = "external_id"
+	ImportKeySlug       = "slug"
+)
+
+const (
+	DefaultImportBatchSize = 100
+	MaxImportBatchSize     = 1000
+	// maxImportLine bounds one JSONL line, i.e. one article
+	maxImportLine = 16 << 20
+)
+
+var (
+	ErrUnknownImportKey = errors.New("key import tidak dikenal: pilih external_id | slug")
+	// ErrInvalidImportFile means the file itself cannot be read any further,
+	// as opposed to a single invalid row
+	ErrInvalidImportFile = errors.New("file import tidak valid")
+)
+
+// ImportOptions controls one Importer.Import run
+type ImportOptions struct {
+	// Format is csv, jsonl or ndjson, like the export formats
+	Format string
+	// Key matches rows to existing articles, which are updated instead of created
+	Key string
+	// DryRun validates and matches every row without writing anything
+	DryRun bool
+	// BatchSize is how many rows are written per transaction
+	BatchSize int
+	// AuthorID is recorded as author of created articles, 0 for none
+	AuthorID int64
+	// Authorize, when set, checks each write like the single-article
+	// endpoints; curr is the matched article, or a new draft for creates
+	Authorize func(action Action, curr Article, status string) error
+}
+
+// ImportRow is one article of an import file. Files written by the export
+// can be imported again; their other columns are ignored.
+type ImportRow struct {
+	ExternalID string `json:"external_id"`
+	Slug       string `json:"slug"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	// ContentFormat empty creates plain articles and keeps the format of updated ones
+	ContentFormat string     `json:"content_format"`
+	Category      string     `json:"category"`
+	Status        string     `json:"status"`
+	Tags          []string   `json:"tags"`
+	PublishAt     *time.Time `json:"publish_at"`
+}
+
+// ImportRowError explains why the row starting at Line was not imported
+type ImportRowError struct {
+	Line       int                       `json:"line"`
+	ExternalID string                    `json:"external_id,omitempty"`
+	Slug       string                    `json:"slug,omitempty"`
+	Error      string                    `json:"error"`
+	Errors     []validatorpkg.FieldError `json:"errors,omitempty"`
+}
+
+// ImportReport summarizes an import. In a dry run Created and Updated count
+// what would have been written.
+type ImportReport struct {
+	DryRun  bool `json:"dry_run"`
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	// Unchanged rows matched an article that already has their values
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+func (r *ImportReport) fail(item importItem, msg string, errs []validatorpkg.FieldError) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Line: item.line, ExternalID: item.ExternalID, Slug: item.Slug, Error: msg, Errors: errs})
+}
+
+// importOutcome is what writing a row did, or would do in a dry run
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importUpdated
+	importUnchanged
+)
+
+// record counts the outcome of a row that was written or checked
+func (r *ImportReport) record(item importItem, outcome importOutcome, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		r.fail(item, "article tidak ditemukan", nil)
+	case err != nil:
+		r.fail(item, err.Error(), nil)
+	case outcome == importCreated:
+		r.Created++
+	case outcome == importUpdated:
+		r.Updated++
+	default:
+		r.Unchanged++
+	}
+}
+
+// importItem is a parsed row with the request it becomes
+type importItem struct {
+	ImportRow
+	line int
+	req  CreateArticleRequest
+	// slug is the fixed slug of a created article, empty to derive it from the title
+	slug string
+}
+
+// Importer upserts articles from csv or jsonl files
+type Importer struct {
+	svc       *Service
+	validator *validatorpkg.Validator
+}
+
+func NewImporter(svc *Service, validator *validatorpkg.Validator) *Importer {
+	return &Importer{svc: svc, validator: validator}
+}
+
+// Import reads every row of r, validates it with the CreateArticleRequest
+// rules and creates or updates the article matched by opts.Key. Valid rows
+// are written in batches of one transaction each; when a batch fails its
+// rows are retried one by one so only the failing rows are reported.
+func (im *Importer) Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	rep := ImportReport{DryRun: opts.DryRun, Errors: make([]ImportRowError, 0)}
+	if !IsValidExportFormat(opts.Format) {
+		return rep, ErrUnknownExportFormat
+	}
+	if opts.Key != ImportKeyExternalID && opts.Key != ImportKeySlug {
+		return rep, ErrUnknownImportKey
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	opts.BatchSize = min(opts.BatchSize, MaxImportBatchSize)
+
+	// keys seen by a dry run, whose rows would update the article an earlier row created
+	seen := make(map[string]bool)
+	batch := make([]importItem, 0, opts.BatchSize)
+	err := readImportRows(r, opts.Format, func(line int, row ImportRow, errs []validatorpkg.FieldError) error {
+		rep.Total++
+		item := importItem{ImportRow: row, line: line}
+		if len(errs) == 0 {
+			errs = im.prepare(&item, opts.Key)
+		}
+		if len(errs) > 0 {
+			rep.fail(item, "baris tidak valid", errs)
+			return nil
+		}
+		if opts.DryRun {
+			outcome, err := im.check(ctx, item, opts, seen)
+			rep.record(item, outcome, err)
+			return nil
+		}
+		if batch = append(batch, item); len(batch) == opts.BatchSize {
+			im.writeBatch(ctx, batch, opts, &rep)
+			batch = batch[:0]
+		}
+		return nil
+	})
+	// rows read before a broken part of the file are written all the same,
+	// so every row counted in Total is in the report
+	im.writeBatch(ctx, batch, opts, &rep)
+	// rows retried after a failed batch are reported after later invalid rows
+	sort.SliceStable(rep.Errors, func(i, j int) bool { return rep.Errors[i].Line < rep.Errors[j].Line })
+	return rep, err
+}
+
+// prepare validates the row and builds its request
+func (im *Importer) prepare(item *importItem, key string) []validatorpkg.FieldError {
+	item.req = CreateArticleRequest{
+		Title:         strings.TrimSpace(item.Title),
+		Content:       item.Content,
+		ContentFormat: strings.ToLower(strings.TrimSpace(item.ContentFormat)),
+		Category:      strings.TrimSpace(item.Category),
+		Status:        strings.ToLower(strings.TrimSpace(item.Status)),
+		Tags:          item.Tags,
+		PublishAt:     item.PublishAt,
+		ExternalID:    strings.TrimSpace(item.ExternalID),
+	}
+	errs, _ := im.validator.ValidateStructDetailed(item.req)
+	errs = append(errs, tagErrors(item.req.Tags)...)
+	switch key {
+	case ImportKeyExternalID:
+		if item.req.ExternalID == "" {
+			errs = append(errs, validatorpkg.FieldError{Field: "external_id", Message: "external_id wajib diisi", Tag: "required"})
+		}
+	case ImportKeySlug:
+		// a given slug is kept for created articles so a re-import matches them
+		if item.slug = slug.Make(item.Slug); item.Slug != "" && item.slug == "" {
+			errs = append(errs, validatorpkg.FieldError{Field: "slug", Message: "slug tidak valid", Tag: "slug", Param: item.Slug})
+		}
+	}
+	return errs
+}
+
+// find looks up the article the row refers to; found is false for new ones
+func (im *Importer) find(ctx context.Context, item importItem, key string) (curr Article, found bool, err error) {
+	if key == ImportKeyExternalID {
+		curr, err = im.svc.repo.FindByExternalID(ctx, item.req.ExternalID)
+	} else {
+		sl := item.slug
+		if sl == "" {
+			sl = slug.Make(item.req.Title)
+		}
+		curr, err = im.svc.repo.FindBySlug(ctx, sl)
+	}
+	if err == sql.ErrNoRows {
+		return Article{}, false, nil
+	}
+	return curr, err == nil, err
+}
+
+// apply creates or updates the article of one row
+func (im *Importer) apply(ctx context.Context, item importItem, opts ImportOptions) (importOutcome, error) {
+	curr, found, err := im.find(ctx, item, opts.Key)
+	if err != nil {
+		return importCreated, err
+	}
+	if !found {
+		if err := im.authorize(opts, ActionCreate, Article{AuthorID: &opts.AuthorID, Status: StatusDraft}, item.req.Status); err != nil {
+			return importCreated, err
+		}
+		_, err := im.svc.create(ctx, item.req, opts.AuthorID, item.slug)
+		return importCreated, err
+	}
+	if unchanged(curr, item.req) {
+		return importUnchanged, nil
+	}
+	if err := im.authorize(opts, ActionUpdate, curr, item.req.Status); err != nil {
+		return importUpdated, err
+	}
+	_, err = im.svc.Update(ctx, curr.ID, updateFromImport(item.req), 0, audit.SourceFrom(ctx).Actor)
+	return importUpdated, err
+}
+
+// check runs the checks of apply without writing
+func (im *Importer) check(ctx context.Context, item importItem, opts ImportOptions, seen map[string]bool) (importOutcome, error) {
+	key := item.req.ExternalID
+	if opts.Key == ImportKeySlug {
+		if key = item.slug; key == "" {
+			key = slug.Make(item.req.Title)
+		}
+	}
+	curr, found, err := im.find(ctx, item, opts.Key)
+	if err != nil {
+		return importCreated, err
+	}
+	if _, err := im.svc.resolveCategory(ctx, item.req.Category); err != nil {
+		return importCreated, err
+	}
+	if !found && !seen[key] {
+		seen[key] = true
+		if err := im.authorize(opts, ActionCreate, Article{AuthorID: &opts.AuthorID, Status: StatusDraft}, item.req.Status); err != nil {
+			return importCreated, err
+		}
+		if item.slug != "" {
+			taken, err := im.svc.repo.SlugTaken(ctx, item.slug, 0)
+			if err != nil {
+				return importCreated, err
+			}
+			if taken {
+				return importCreated, ErrSlugConflict
+			}
+		}
+		if !isEntryStatus(item.req.Status) {
+			return importCreated, ErrInvalidEntryStatus
+		}
+		a := Article{Status: item.req.Status}
+		return importCreated, applySchedule(&a, "", item.req.PublishAt)
+	}
+	if !found {
+		// updates an article created by an earlier row of this file
+		return importUpdated, nil
+	}
+	if unchanged(curr, item.req) {
+		return importUnchanged, nil
+	}
+	if err := im.authorize(opts, ActionUpdate, curr, item.req.Status); err != nil {
+		return importUpdated, err
+	}
+	up := curr
+	if item.req.Status != curr.Status {
+		if _, err := checkStatusChange(curr.Status, item.req.Status, ""); err != nil {
+			return importUpdated, err
+		}
+		up.Status = item.req.Status
+	}
+	return importUpdated, applySchedule(&up, curr.Status, item.req.PublishAt)
+}
+
+func (im *Importer) authorize(opts ImportOptions, action Action, curr Article, status string) error {
+	if opts.Authorize == nil {
+		return nil
+	}
+	return opts.Authorize(action, curr, status)
+}
+
+// writeBatch writes batch in one transaction, or row by row when it fails
+func (im *Importer) writeBatch(ctx context.Context, batch []importItem, opts ImportOptions, rep *ImportReport) {
+	if len(batch) == 0 {
+		return
+	}
+	outcomes := make([]importOutcome, len(batch))
+	err := im.svc.withinTx(ctx, func(ctx context.Context) error {
+		for i, item := range batch {
+			var err error
+			if outcomes[i], err = im.apply(ctx, item, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for i, item := range batch {
+			rep.record(item, outcomes[i], nil)
+		}
+		return
+	}
+	// the batch was rolled back: retry each row on its own to report the failing ones
+	for _, item := range batch {
+		outcome, err := im.apply(ctx, item, opts)
+		rep.record(item, outcome, err)
+	}
+}
+
+// unchanged reports whether curr already has every value req would write
+func unchanged(curr Article, req CreateArticleRequest) bool {
+	if curr.Title != req.Title || curr.Content != req.Content || curr.Status != req.Status ||
+		slug.Make(curr.Category) != slug.Make(req.Category) || (req.ExternalID != "" && curr.ExternalID != req.ExternalID) ||
+		(req.ContentFormat != "" && curr.ContentFormat != req.ContentFormat) {
+		return false
+	}
+	if (curr.PublishAt == nil) != (req.PublishAt == nil) || (curr.PublishAt != nil && !curr.PublishAt.Equal(*req.PublishAt)) {
+		return false
+	}
+	tags := normalizeTags(req.Tags)
+	if len(tags) != len(curr.Tags) {
+		return false
+	}
+	for _, t := range tags {
+		if !slices.ContainsFunc(curr.Tags, func(name string) bool { return slug.Make(name) == t.Slug }) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateFromImport overwrites every imported field of the matched article
+func updateFromImport(req CreateArticleRequest) UpdateArticleRequest {
+	return UpdateArticleRequest{
+		Title:         req.Title,
+		Content:       req.Content,
+		ContentFormat: req.ContentFormat,
+		Category:      req.Category,
+		Status:        req.Status,
+		Tags:          tagsOrEmpty(req.Tags),
+		PublishAt:     req.PublishAt,
+		ExternalID:    req.ExternalID,
+	}
+}
+
+// readImportRows calls fn for every row of r with the line it starts at.
+// Values that cannot be decoded are passed as field errors of the row;
+// only a file that cannot be read any further returns an error.
+func readImportRows(r io.Reader, format string, fn func(line int, row ImportRow, errs []validatorpkg.FieldError) error) error {
+	if format == ExportCSV {
+		return readImportCSV(r, fn)
+	}
+	return readImportJSONL(r, fn)
+}
+
+func readImportCSV(r io.Reader, fn func(line int, row ImportRow, errs []validatorpkg.FieldError) error) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: header: %v", ErrInvalidImportFile, err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheet tools may start the file with a UTF-8 BOM
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return fmt.Errorf("%w: header tidak memiliki kolom title", ErrInvalidImportFile)
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var errs []validatorpkg.FieldError
+		if err != nil {
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) || pe.Err != csv.ErrFieldCount {
+				return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+			}
+			errs = append(errs, validatorpkg.FieldError{Field: "row", Message: fmt.Sprintf("jumlah kolom harus %d", len(header)), Tag: "len"})
+		}
+		// only a record that was read has field positions
+		line, _ := cr.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				// undo the formula escaping of the export
+				return uncsvCell(rec[i])
+			}
+			return ""
+		}
+		row := ImportRow{
+			ExternalID:    get("external_id"),
+			Slug:          get("slug"),
+			Title:         get("title"),
+			Content:       get("content"),
+			ContentFormat: get("content_format"),
+			Category:      get("category"),
+			Status:        get("status"),
+			Tags:          splitImportTags(get("tags")),
+		}
+		if v := strings.TrimSpace(get("publish_at")); v != "" {
+			t, errTime := time.Parse(time.RFC3339, v)
+			if errTime != nil {
+				errs = append(errs, validatorpkg.FieldError{Field: "publish_at", Message: "publish_at harus berformat RFC3339", Tag: "datetime", Param: v})
+			}
+			row.PublishAt = &t
+		}
+		if err := fn(line, row, errs); err != nil {
+			return err
+		}
+	}
+}
+
+// splitImportTags splits the tags column, joined with "|" like the export
+func splitImportTags(v string) []string {
+	res := make([]string, 0)
+	for _, t := range strings.Split(v, "|") {
+		if t = strings.TrimSpace(t); t != "" {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func readImportJSONL(r io.Reader, fn func(line int, row ImportRow, errs []validatorpkg.FieldError) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxImportLine)
+	for line := 1; sc.Scan(); line++ {
+		data := strings.TrimSpace(sc.Text())
+		if data == "" {
+			continue
+		}
+		var row ImportRow
+		var errs []validatorpkg.FieldError
+		if err := json.Unmarshal([]byte(data), &row); err != nil {
+			errs = append(errs, validatorpkg.FieldError{Field: "row", Message: "baris bukan JSON object artikel yang valid", Tag: "json"})
+		}
+		if err := fn(line, row, errs); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	return nil
+}
+
+// WriteImportErrors writes the failed rows of rep as CSV, one line per field error
+func WriteImportErrors(w io.Writer, rep ImportReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"line", "external_id", "slug", "field", "error"}); err != nil {
+		return err
+	}
+	for _, e := range rep.Errors {
+		line := fmt.Sprint(e.Line)
+		if len(e.Errors) == 0 {
+			if err := cw.Write([]string{line, csvCell(e.ExternalID), csvCell(e.Slug), "", csvCell(e.Error)}); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, fe := range e.Errors {
+			if err := cw.Write([]string{line, csvCell(e.ExternalID), csvCell(e.Slug), fe.Field, csvCell(fe.Message)}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
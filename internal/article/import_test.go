@@ -0,0 +1,203 @@
+package article
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+const importContent = "lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet lorem ipsum dolor sit amet"
+
+func TestImportFlushesRowsBeforeBrokenPart(t *testing.T) {
+	env := newTestEnv(t)
+	im := NewImporter(env.svc, validatorpkg.NewValidator())
+
+	file := "external_id,title,content,category,status\n" +
+		"a-1,First imported article title," + importContent + ",Tech,draft\n" +
+		"a-2,Second imported article title," + importContent + ",News,draft\n" +
+		// a bare quote makes the rest of the file unreadable
+		"a-3,Third \"broken\" article title," + importContent + ",Tech,draft\n" +
+		"a-4,Fourth imported article title," + importContent + ",Tech,draft\n"
+	rep, err := im.Import(context.Background(), strings.NewReader(file), ImportOptions{Format: ExportCSV, Key: ImportKeyExternalID, BatchSize: 10})
+	if !errors.Is(err, ErrInvalidImportFile) {
+		t.Fatalf("Import error = %v, want ErrInvalidImportFile", err)
+	}
+	if rep.Total != 2 || rep.Created != 2 || rep.Failed != 0 {
+		t.Fatalf("report = %+v, want the 2 rows before the broken one created", rep)
+	}
+	for _, id := range []string{"a-1", "a-2"} {
+		if _, err := env.repo.FindByExternalID(context.Background(), id); err != nil {
+			t.Errorf("article %s: %v", id, err)
+		}
+	}
+}
+
+// parsedRow is what readImportRows passes for one row
+type parsedRow struct {
+	line int
+	row  ImportRow
+	errs []validatorpkg.FieldError
+}
+
+func readAll(t *testing.T, format, file string) ([]parsedRow, error) {
+	t.Helper()
+	var rows []parsedRow
+	err := readImportRows(strings.NewReader(file), format, func(line int, row ImportRow, errs []validatorpkg.FieldError) error {
+		rows = append(rows, parsedRow{line, row, errs})
+		return nil
+	})
+	return rows, err
+}
+
+func TestReadImportCSV(t *testing.T) {
+	file := "\ufeffTitle , slug,tags,publish_at,unknown,status\n" +
+		"First,first-slug, go | web ||,2030-01-02T03:04:05Z,x,scheduled\n" +
+		"\"Multi\nline\",,,,,draft\n" +
+		"Bad time,,,tomorrow,,draft\n" +
+		"Short row\n"
+	rows, err := readAll(t, ExportCSV, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+
+	first := rows[0]
+	if first.line != 2 || first.row.Title != "First" || first.row.Slug != "first-slug" || first.row.Status != "scheduled" || len(first.errs) != 0 {
+		t.Errorf("first row = %+v", first)
+	}
+	if strings.Join(first.row.Tags, ",") != "go,web" {
+		t.Errorf("tags = %q", first.row.Tags)
+	}
+	if first.row.PublishAt == nil || first.row.PublishAt.Year() != 2030 {
+		t.Errorf("publish_at = %v", first.row.PublishAt)
+	}
+	// a quoted field may span lines; the next row starts further down
+	if rows[1].line != 3 || rows[1].row.Title != "Multi\nline" || rows[2].line != 5 {
+		t.Errorf("lines = %d, %d", rows[1].line, rows[2].line)
+	}
+	if len(rows[2].errs) != 1 || rows[2].errs[0].Field != "publish_at" {
+		t.Errorf("bad publish_at errors = %+v", rows[2].errs)
+	}
+	if len(rows[3].errs) != 1 || rows[3].errs[0].Field != "row" || rows[3].row.Title != "Short row" {
+		t.Errorf("short row = %+v", rows[3])
+	}
+}
+
+func TestReadImportCSVInvalidFile(t *testing.T) {
+	cases := map[string]string{
+		"no title column": "external_id,content\na,b\n",
+		"bare quote":      "title\nok\nbro\"ken\n",
+		"unclosed quote":  "title\n\"never closed\n",
+	}
+	for name, file := range cases {
+		if _, err := readAll(t, ExportCSV, file); !errors.Is(err, ErrInvalidImportFile) {
+			t.Errorf("%s: error = %v, want ErrInvalidImportFile", name, err)
+		}
+	}
+	if rows, err := readAll(t, ExportCSV, ""); err != nil || len(rows) != 0 {
+		t.Errorf("empty file = %v, %v", rows, err)
+	}
+}
+
+func TestReadImportJSONL(t *testing.T) {
+	file := `{"title":"First","tags":["go"],"publish_at":"2030-01-02T03:04:05Z","id":9}` + "\n" +
+		"\n" +
+		`not json` + "\n" +
+		`{"title":"Fourth","tags":"go"}` + "\r\n" +
+		`  {"title":"Fifth"}  `
+	rows, err := readAll(t, ExportJSONL, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4", len(rows))
+	}
+	if rows[0].line != 1 || rows[0].row.Title != "First" || rows[0].row.PublishAt == nil || len(rows[0].errs) != 0 {
+		t.Errorf("first row = %+v", rows[0])
+	}
+	// blank lines are skipped but still counted
+	for i, want := range []int{3, 4} {
+		r := rows[i+1]
+		if r.line != want || len(r.errs) != 1 || r.errs[0].Field != "row" {
+			t.Errorf("row on line %d = %+v", want, r)
+		}
+	}
+	if rows[3].line != 5 || rows[3].row.Title != "Fifth" || len(rows[3].errs) != 0 {
+		t.Errorf("last row = %+v", rows[3])
+	}
+
+	long := `{"title":"` + strings.Repeat("x", maxImportLine) + `"}`
+	if _, err := readAll(t, ExportJSONL, long); !errors.Is(err, ErrInvalidImportFile) {
+		t.Errorf("line over the limit = %v, want ErrInvalidImportFile", err)
+	}
+}
+
+func TestCSVExportEscapesFormulas(t *testing.T) {
+	a := Article{
+		ExternalID: "+1-555",
+		Title:      "=HYPERLINK(\"http://evil\")",
+		Content:    "- first item\n- second item",
+		Category:   "@Tech",
+		Tags:       []string{"-go", "web"},
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	var buf bytes.Buffer
+	ew, err := NewExportWriter(&buf, ExportCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{a.Title, "'=already quoted", "'plain quote", "Plain title"} {
+		a.Title = title
+		if err := ew.Write(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ew.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"external_id": "'+1-555",
+		"title":       "'=HYPERLINK(\"http://evil\")",
+		"content":     "'- first item\n- second item",
+		"category":    "'@Tech",
+		"tags":        "'-go|web",
+	}
+	for i, col := range exportColumns {
+		if w, ok := want[col]; ok && recs[1][i] != w {
+			t.Errorf("column %s = %q, want %q", col, recs[1][i], w)
+		}
+	}
+	if recs[2][2] != "''=already quoted" || recs[3][2] != "'plain quote" || recs[4][2] != "Plain title" {
+		t.Errorf("titles = %q %q %q", recs[2][2], recs[3][2], recs[4][2])
+	}
+
+	// importing the export gives back the original values
+	rows, err := readAll(t, ExportCSV, buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTitles := []string{"=HYPERLINK(\"http://evil\")", "'=already quoted", "'plain quote", "Plain title"}
+	for i, r := range rows {
+		if r.row.Title != wantTitles[i] {
+			t.Errorf("imported title = %q, want %q", r.row.Title, wantTitles[i])
+		}
+	}
+	first := rows[0].row
+	if first.ExternalID != a.ExternalID || first.Content != a.Content || first.Category != a.Category || strings.Join(first.Tags, "|") != "-go|web" {
+		t.Errorf("imported row = %+v", first)
+	}
+}
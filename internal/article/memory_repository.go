@@ -0,0 +1,634 @@
+package article
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+// MemoryRepository menyimpan artikel di memory. Dipakai untuk test dan
+// local dev tanpa MySQL; semantik filter dan error mengikuti MySQLRepository.
+type MemoryRepository struct {
+	mu sync.RWMutex
+	// txMu runs one WithinTx at a time, so a rollback only restores the
+	// writes of its own transaction
+	txMu      sync.Mutex
+	nextID    int64
+	nextRevID int64
+	items     map[int64]Article
+	revisions map[int64][]Revision
+	// transitions is the workflow history per article
+	transitions      map[int64][]TransitionRecord
+	nextTransitionID int64
+	// oldSlugs maps historical slugs to their article, like article_slugs
+	oldSlugs map[string]int64
+	// tags is the tag registry keyed by tag slug
+	tags      map[string]Tag
+	nextTagID int64
+	// categories supplies the current category names, like the categories join
+	categories CategoryLookup
+}
+
+func NewMemoryRepository(categories CategoryLookup) *MemoryRepository {
+	return &MemoryRepository{
+		categories:  categories,
+		items:       make(map[int64]Article),
+		revisions:   make(map[int64][]Revision),
+		transitions: make(map[int64][]TransitionRecord),
+		oldSlugs:    make(map[string]int64),
+		tags:        make(map[string]Tag),
+	}
+}
+
+// WithinTx runs fn in a database.WithMemTx transaction and restores the
+// articles as they were before fn when it fails. Memory repositories of other
+// packages written with the same ctx roll back along.
+func (r *MemoryRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if database.InMemTx(ctx) {
+		return fn(ctx)
+	}
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+
+	return database.WithMemTx(ctx, func(ctx context.Context) error {
+		database.OnRollback(ctx, r.restorer())
+		return fn(ctx)
+	})
+}
+
+// restorer snapshots the repository and returns a func putting it back
+func (r *MemoryRepository) restorer() func() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// the revision and transition slices are only appended to, sharing them
+	// with the snapshot is safe
+	nextID, nextRevID, nextTransitionID, nextTagID := r.nextID, r.nextRevID, r.nextTransitionID, r.nextTagID
+	items, revisions, transitions := maps.Clone(r.items), maps.Clone(r.revisions), maps.Clone(r.transitions)
+	oldSlugs, tags := maps.Clone(r.oldSlugs), maps.Clone(r.tags)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.nextID, r.nextRevID, r.nextTransitionID, r.nextTagID = nextID, nextRevID, nextTransitionID, nextTagID
+		r.items, r.revisions, r.transitions = items, revisions, transitions
+		r.oldSlugs, r.tags = oldSlugs, tags
+	}
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, in Article) (Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, taken := r.slugOwner(in.Slug); taken {
+		return Article{}, ErrSlugConflict
+	}
+	if _, taken := r.externalIDOwner(in.ExternalID); taken {
+		return Article{}, ErrExternalIDConflict
+	}
+
+	r.nextID++
+	now := memNow()
+	a := Article{
+		ID:            r.nextID,
+		Title:         in.Title,
+		Slug:          in.Slug,
+		ExternalID:    in.ExternalID,
+		Content:       in.Content,
+		ContentFormat: in.ContentFormat,
+		WordCount:     in.WordCount,
+		ReadingTime:   in.ReadingTime,
+		Excerpt:       in.Excerpt,
+		CategoryID:    in.CategoryID,
+		Category:      in.Category,
+		AuthorID:      in.AuthorID,
+		PublishAt:     in.PublishAt,
+		Tags:          r.resolveTags(in.Tags),
+		Status:        in.Status,
+		Version:       1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	r.items[a.ID] = a
+	r.addRevision(a)
+	return a, nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context, q ListQuery) ([]Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := r.filtered(ctx, q.Filter)
+	if len(q.Sort) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool { return compareArticles(matched[i], matched[j], q.Sort) < 0 })
+	}
+	offset := q.Offset
+	if q.Cursor != nil {
+		key, err := keyValues(q.Sort, *q.Cursor)
+		if err != nil {
+			return []Article{}, err
+		}
+		offset = 0
+		seek := make([]Article, 0, len(matched))
+		if q.Cursor.Backward {
+			for i := len(matched) - 1; i >= 0; i-- {
+				if compareToKey(matched[i], q.Sort, key) < 0 {
+					seek = append(seek, matched[i])
+				}
+			}
+		} else {
+			for _, a := range matched {
+				if compareToKey(a, q.Sort, key) > 0 {
+					seek = append(seek, a)
+				}
+			}
+		}
+		matched = seek
+	}
+
+	if offset >= len(matched) {
+		return []Article{}, nil
+	}
+	end := min(offset+q.Limit, len(matched))
+	res := make([]Article, 0, end-offset)
+	res = append(res, matched[offset:end]...)
+	return res, nil
+}
+
+// Export iterates a snapshot of the matching articles, so fn runs without
+// holding the lock
+func (r *MemoryRepository) Export(ctx context.Context, filter ListFilter, fn func(Article) error) error {
+	r.mu.RLock()
+	items := r.filtered(ctx, filter)
+	r.mu.RUnlock()
+	for _, a := range items {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) Search(ctx context.Context, q SearchQuery) ([]SearchHit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	terms := parseSearchTerms(q.Query, q.Mode == SearchModeBoolean)
+	hits := make([]SearchHit, 0)
+	for _, a := range r.filtered(ctx, q.Filter) {
+		if score := scoreText(a.Title, a.Content, terms); score > 0 {
+			hits = append(hits, SearchHit{Article: a, Score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+
+	if q.Offset >= len(hits) {
+		return []SearchHit{}, nil
+	}
+	return hits[q.Offset:min(q.Offset+q.Limit, len(hits))], nil
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.items[id]
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	return r.withCategory(ctx, a), nil
+}
+
+// FindByIDForUpdate is FindByID: WithinTx runs one transaction at a time here
+func (r *MemoryRepository) FindByIDForUpdate(ctx context.Context, id int64) (Article, error) {
+	return r.FindByID(ctx, id)
+}
+
+// FindByIDFields returns every field; the handler leaves out the others
+func (r *MemoryRepository) FindByIDFields(ctx context.Context, id int64, _ []string) (Article, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *MemoryRepository) UpdateAll(ctx context.Context, in Article, expectedVersion int64) (Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := in.ID
+	a, ok := r.items[id]
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	if expectedVersion != 0 && a.Version != expectedVersion {
+		return Article{}, ErrVersionMismatch
+	}
+	if owner, taken := r.slugOwner(in.Slug); taken && owner != id {
+		return Article{}, ErrSlugConflict
+	}
+	if owner, taken := r.externalIDOwner(in.ExternalID); taken && owner != id {
+		return Article{}, ErrExternalIDConflict
+	}
+	if a.Slug != in.Slug {
+		r.oldSlugs[a.Slug] = id
+		delete(r.oldSlugs, in.Slug)
+	}
+	a.Version++
+	a.Title = in.Title
+	a.Slug = in.Slug
+	a.ExternalID = in.ExternalID
+	a.Content = in.Content
+	a.ContentFormat = in.ContentFormat
+	a.WordCount = in.WordCount
+	a.ReadingTime = in.ReadingTime
+	a.Excerpt = in.Excerpt
+	a.CategoryID = in.CategoryID
+	a.Category = in.Category
+	a.Tags = r.resolveTags(in.Tags)
+	a.Status = in.Status
+	a.PublishAt = in.PublishAt
+	a.UpdatedAt = memNow()
+	r.items[id] = a
+	r.addRevision(a)
+	return a, nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return sql.ErrNoRows
+	}
+	r.deleteArticle(id)
+	return nil
+}
+
+func (r *MemoryRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.filtered(ctx, filter))), nil
+}
+
+func (r *MemoryRepository) CountByCategory(ctx context.Context, categoryID int64) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n int64
+	for _, a := range r.items {
+		if a.CategoryID == categoryID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) Trash(ctx context.Context, id int64, actor string) (Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.items[id]
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	if a.Status == StatusThrash {
+		return Article{}, ErrAlreadyTrashed
+	}
+	now := memNow()
+	a.PreviousStatus = a.Status
+	a.Status = StatusThrash
+	a.TrashedAt = &now
+	a.TrashedBy = actor
+	a.Version++
+	a.UpdatedAt = now
+	r.items[id] = a
+	return r.withCategory(ctx, a), nil
+}
+
+func (r *MemoryRepository) Restore(ctx context.Context, id int64) (Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.items[id]
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	if a.Status != StatusThrash {
+		return Article{}, ErrNotTrashed
+	}
+	a.Status = StatusDraft
+	a.PreviousStatus = ""
+	a.TrashedAt = nil
+	a.TrashedBy = ""
+	a.Version++
+	a.UpdatedAt = memNow()
+	r.items[id] = a
+	return r.withCategory(ctx, a), nil
+}
+
+func (r *MemoryRepository) PurgeTrashed(ctx context.Context, before time.Time) ([]Article, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := make([]Article, 0)
+	for id, a := range r.items {
+		if a.Status == StatusThrash && a.TrashedAt != nil && a.TrashedAt.Before(before) {
+			purged = append(purged, r.withCategory(ctx, a))
+			r.deleteArticle(id)
+		}
+	}
+	return purged, nil
+}
+
+func (r *MemoryRepository) PublishDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]Article, 0)
+	for _, a := range r.items {
+		if a.Status == StatusScheduled && a.PublishAt != nil && !a.PublishAt.After(now) {
+			due = append(due, a)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].PublishAt.Equal(*due[j].PublishAt) {
+			return due[i].PublishAt.Before(*due[j].PublishAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	ids := make([]int64, 0, limit)
+	for _, a := range due[:min(limit, len(due))] {
+		a.Status = StatusPublish
+		a.Version++
+		a.UpdatedAt = memNow()
+		r.items[a.ID] = a
+		r.addRevision(r.withCategory(ctx, a))
+		ids = append(ids, a.ID)
+	}
+	return ids, nil
+}
+
+func (r *MemoryRepository) AddTransition(ctx context.Context, t TransitionRecord) (TransitionRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[t.ArticleID]; !ok {
+		return TransitionRecord{}, sql.ErrNoRows
+	}
+	r.nextTransitionID++
+	t.ID = r.nextTransitionID
+	t.CreatedAt = memNow()
+	r.transitions[t.ArticleID] = append(r.transitions[t.ArticleID], t)
+	return t, nil
+}
+
+func (r *MemoryRepository) ListTransitions(ctx context.Context, articleID int64) ([]TransitionRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]TransitionRecord, len(r.transitions[articleID]))
+	copy(res, r.transitions[articleID])
+	return res, nil
+}
+
+func (r *MemoryRepository) ListRevisions(ctx context.Context, articleID int64) ([]Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]Revision, len(r.revisions[articleID]))
+	copy(res, r.revisions[articleID])
+	return res, nil
+}
+
+func (r *MemoryRepository) FindRevision(ctx context.Context, articleID int64, revision int) (Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rev := range r.revisions[articleID] {
+		if rev.Revision == revision {
+			return rev, nil
+		}
+	}
+	return Revision{}, sql.ErrNoRows
+}
+
+func (r *MemoryRepository) FindBySlug(ctx context.Context, slug string) (Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.slugOwner(slug)
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	return r.withCategory(ctx, r.items[id]), nil
+}
+
+func (r *MemoryRepository) FindByExternalID(ctx context.Context, externalID string) (Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.externalIDOwner(externalID)
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	return r.withCategory(ctx, r.items[id]), nil
+}
+
+func (r *MemoryRepository) FindByOldSlug(ctx context.Context, slug string) (Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.oldSlugs[slug]
+	if !ok {
+		return Article{}, sql.ErrNoRows
+	}
+	return r.withCategory(ctx, r.items[id]), nil
+}
+
+func (r *MemoryRepository) SlugTaken(ctx context.Context, slug string, exceptID int64) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if id, ok := r.slugOwner(slug); ok && id != exceptID {
+		return true, nil
+	}
+	if id, ok := r.oldSlugs[slug]; ok && id != exceptID {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *MemoryRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int64, len(r.tags))
+	for _, a := range r.items {
+		for _, name := range a.Tags {
+			counts[slug.Make(name)]++
+		}
+	}
+	res := make([]Tag, 0, len(r.tags))
+	for s, t := range r.tags {
+		t.ArticleCount = counts[s]
+		res = append(res, t)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ArticleCount != res[j].ArticleCount {
+			return res[i].ArticleCount > res[j].ArticleCount
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// resolveTags registers unknown tags and returns the stored names, sorted.
+// Caller must hold r.mu.
+func (r *MemoryRepository) resolveTags(names []string) []string {
+	res := make([]string, 0, len(names))
+	for _, t := range normalizeTags(names) {
+		stored, ok := r.tags[t.Slug]
+		if !ok {
+			r.nextTagID++
+			stored = Tag{ID: r.nextTagID, Name: t.Name, Slug: t.Slug}
+			r.tags[t.Slug] = stored
+		}
+		res = append(res, stored.Name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// withCategory fills in the current name of the article's category, which
+// may have been renamed since the article was written.
+func (r *MemoryRepository) withCategory(ctx context.Context, a Article) Article {
+	if c, err := r.categories.FindByID(ctx, a.CategoryID); err == nil {
+		a.Category = c.Name
+	}
+	return a
+}
+
+// slugOwner finds the article currently using slug. Caller must hold r.mu.
+func (r *MemoryRepository) slugOwner(slug string) (int64, bool) {
+	for id, a := range r.items {
+		if a.Slug == slug {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// externalIDOwner finds the article imported with externalID; an empty id
+// is never taken. Caller must hold r.mu.
+func (r *MemoryRepository) externalIDOwner(externalID string) (int64, bool) {
+	if externalID == "" {
+		return 0, false
+	}
+	for id, a := range r.items {
+		if a.ExternalID == externalID {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// deleteArticle removes an article with everything that cascades from it.
+// Caller must hold r.mu.
+func (r *MemoryRepository) deleteArticle(id int64) {
+	delete(r.items, id)
+	delete(r.revisions, id)
+	delete(r.transitions, id)
+	for slug, owner := range r.oldSlugs {
+		if owner == id {
+			delete(r.oldSlugs, slug)
+		}
+	}
+}
+
+// addRevision snapshots a as its next revision. Caller must hold r.mu.
+func (r *MemoryRepository) addRevision(a Article) {
+	r.nextRevID++
+	r.revisions[a.ID] = append(r.revisions[a.ID], Revision{
+		ID:            r.nextRevID,
+		ArticleID:     a.ID,
+		Revision:      int(a.Version),
+		Title:         a.Title,
+		Content:       a.Content,
+		ContentFormat: a.ContentFormat,
+		Category:      a.Category,
+		Status:        a.Status,
+		CreatedAt:     a.UpdatedAt,
+	})
+}
+
+// filtered returns articles matching filter ordered by id, same as ORDER BY id.
+// Caller must hold r.mu.
+func (r *MemoryRepository) filtered(ctx context.Context, filter ListFilter) []Article {
+	res := make([]Article, 0, len(r.items))
+	for _, a := range r.items {
+		a = r.withCategory(ctx, a)
+		if matchFilter(a, filter) {
+			res = append(res, a)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// matchFilter is the in-memory counterpart of buildFilterClause
+func matchFilter(a Article, filter ListFilter) bool {
+	title := strings.ToLower(strings.TrimSpace(filter.Title))
+	if title != "" && !strings.Contains(strings.ToLower(a.Title), title) {
+		return false
+	}
+	content := strings.ToLower(strings.TrimSpace(filter.Content))
+	if content != "" && !strings.Contains(strings.ToLower(a.Content), content) {
+		return false
+	}
+	if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, slug.Make(a.Category)) {
+		return false
+	}
+	if slices.Contains(filter.NotCategories, slug.Make(a.Category)) {
+		return false
+	}
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, a.Status) {
+		return false
+	}
+	if slices.Contains(filter.NotStatuses, a.Status) {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		matched := 0
+		for _, name := range a.Tags {
+			if slices.Contains(filter.Tags, slug.Make(name)) {
+				matched++
+			}
+		}
+		if matched == 0 || (filter.TagMatch == TagMatchAll && matched < len(filter.Tags)) {
+			return false
+		}
+	}
+	if filter.CreatedFrom != nil && a.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && a.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	if filter.UpdatedSince != nil && a.UpdatedAt.Before(*filter.UpdatedSince) {
+		return false
+	}
+	return true
+}
+
+// memNow mimics MySQL TIMESTAMP precision so both repositories return
+// comparable values.
+func memNow() time.Time {
+	return time.Now().Truncate(time.Second)
+}
@@ -2,12 +2,78 @@ package article
 
 import "time"
 
+const (
+	StatusPublish = "publish"
+	StatusDraft   = "draft"
+	StatusThrash  = "thrash"
+	// StatusScheduled articles are published by the Scheduler once PublishAt passes
+	StatusScheduled = "scheduled"
+	// StatusReview articles wait for an editor to approve or reject them
+	StatusReview = "review"
+)
+
+// Statuses lists every valid article status
+var Statuses = []string{StatusPublish, StatusDraft, StatusThrash, StatusScheduled, StatusReview}
+
+func IsValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type Article struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+	// ExternalID identifies the article in the system it was imported from
+	ExternalID string `json:"external_id,omitempty"`
+	Content    string `json:"content"`
+	// ContentFormat is plain, markdown or html, see render.Formats
+	ContentFormat string `json:"content_format"`
+	// WordCount, ReadingTime (minutes) and Excerpt are computed from the
+	// rendered content on every write
+	WordCount      int        `json:"word_count"`
+	ReadingTime    int        `json:"reading_time"`
+	Excerpt        string     `json:"excerpt"`
+	CategoryID     int64      `json:"category_id"`
+	Category       string     `json:"category"`
+	AuthorID       *int64     `json:"author_id"`
+	Tags           []string   `json:"tags"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Status         string     `json:"status"`
+	PublishAt      *time.Time `json:"publish_at,omitempty"`
+	Version        int64      `json:"version"`
+	PreviousStatus string     `json:"previous_status,omitempty"`
+	TrashedAt      *time.Time `json:"trashed_at,omitempty"`
+	TrashedBy      string     `json:"trashed_by,omitempty"`
+}
+
+// Revision is an immutable snapshot of an article written on every create and update.
+type Revision struct {
+	ID        int64  `json:"id"`
+	ArticleID int64  `json:"article_id"`
+	Revision  int    `json:"revision"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	// ContentFormat is restored together with Content
+	ContentFormat string    `json:"content_format"`
+	Category      string    `json:"category"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TransitionRecord is one entry of an article's workflow history
+type TransitionRecord struct {
 	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Category  string    `json:"category"`
+	ArticleID int64     `json:"article_id"`
+	Action    string    `json:"action"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Comment   string    `json:"comment,omitempty"`
+	Actor     string    `json:"actor"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Status    string    `json:"status"`
 }
@@ -0,0 +1,96 @@
+package article
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+)
+
+// access is the expected outcome of an action on the four kinds of article
+type access struct {
+	ownDraft, otherDraft, ownPublished, otherPublished bool
+}
+
+var (
+	allowAll  = access{true, true, true, true}
+	denyAll   = access{}
+	ownDrafts = access{ownDraft: true}
+)
+
+func TestDefaultPolicyRules(t *testing.T) {
+	want := map[auth.Role]map[Action]access{
+		auth.RoleAdmin: {
+			ActionCreate: allowAll, ActionUpdate: allowAll, ActionPublish: allowAll,
+			ActionTrash: allowAll, ActionRestore: allowAll, ActionPurge: allowAll,
+		},
+		auth.RoleEditor: {
+			ActionCreate: allowAll, ActionUpdate: allowAll, ActionPublish: allowAll,
+			ActionTrash: allowAll, ActionRestore: allowAll, ActionPurge: denyAll,
+		},
+		auth.RoleWriter: {
+			ActionCreate: ownDrafts, ActionUpdate: ownDrafts, ActionPublish: denyAll,
+			ActionTrash: denyAll, ActionRestore: denyAll, ActionPurge: denyAll,
+		},
+		auth.RoleReader: {
+			ActionCreate: denyAll, ActionUpdate: denyAll, ActionPublish: denyAll,
+			ActionTrash: denyAll, ActionRestore: denyAll, ActionPurge: denyAll,
+		},
+		// a principal without role, e.g. a token issued before roles existed
+		"": {
+			ActionCreate: denyAll, ActionUpdate: denyAll, ActionPublish: denyAll,
+			ActionTrash: denyAll, ActionRestore: denyAll, ActionPurge: denyAll,
+		},
+	}
+
+	const userID, otherID int64 = 1, 2
+	pol := NewPolicy(DefaultPolicyRules)
+	for role, actions := range want {
+		for action, acc := range actions {
+			cases := []struct {
+				author int64
+				status string
+				want   bool
+			}{
+				{userID, StatusDraft, acc.ownDraft},
+				{otherID, StatusDraft, acc.otherDraft},
+				{userID, StatusPublish, acc.ownPublished},
+				{otherID, StatusPublish, acc.otherPublished},
+			}
+			for _, tc := range cases {
+				owner := "own"
+				if tc.author != userID {
+					owner = "other"
+				}
+				t.Run(fmt.Sprintf("%s/%s/%s_%s", roleName(role), action, owner, tc.status), func(t *testing.T) {
+					author := tc.author
+					p := auth.Principal{UserID: userID, Role: role}
+					a := Article{AuthorID: &author, Status: tc.status}
+					if got := pol.Can(p, action, a); got != tc.want {
+						t.Errorf("Can = %v, want %v", got, tc.want)
+					}
+					err := pol.Authorize(p, action, a)
+					if tc.want && err != nil {
+						t.Errorf("Authorize = %v, want nil", err)
+					}
+					if !tc.want && !errors.Is(err, ErrForbidden) {
+						t.Errorf("Authorize = %v, want ErrForbidden", err)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestPolicyWithoutAuthor(t *testing.T) {
+	// articles created by API keys have no author: no writer owns them
+	pol := NewPolicy(DefaultPolicyRules)
+	p := auth.Principal{UserID: 1, Role: auth.RoleWriter}
+	if pol.Can(p, ActionUpdate, Article{Status: StatusDraft}) {
+		t.Error("writer may update a draft without author")
+	}
+	if !pol.Can(auth.Principal{Role: auth.RoleEditor}, ActionUpdate, Article{Status: StatusDraft}) {
+		t.Error("editor may not update a draft without author")
+	}
+}
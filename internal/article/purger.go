@@ -0,0 +1,50 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+)
+
+// Purger periodically removes articles that stayed in trash longer than retention.
+type Purger struct {
+	svc       *Service
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(svc *Service, retention, interval time.Duration) *Purger {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Purger{svc: svc, retention: retention, interval: interval}
+}
+
+// Run blocks until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	n, err := p.svc.PurgeTrashed(ctx, p.retention)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Log.WithError(err).Error("purge trashed articles failed")
+		}
+		return
+	}
+	if n > 0 {
+		logger.Log.WithFields(map[string]interface{}{"purged": n, "retention": p.retention.String()}).Info("trashed articles purged")
+	}
+}
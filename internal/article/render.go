@@ -0,0 +1,102 @@
+package article
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/render"
+)
+
+// RenderHTML is the value of the render query param returning content_html
+const RenderHTML = "html"
+
+// renderCacheSize bounds the rendered revisions kept in memory
+const renderCacheSize = 512
+
+// WordsPerMinute is the reading speed ReadingTime is estimated with
+const WordsPerMinute = 200
+
+// RenderedArticle is an article with its content rendered to sanitized HTML
+type RenderedArticle struct {
+	Article
+	ContentHTML string           `json:"content_html"`
+	TOC         []render.Heading `json:"toc"`
+}
+
+// renderKey identifies a revision: every write increments the version
+type renderKey struct {
+	id      int64
+	version int64
+}
+
+type renderEntry struct {
+	key renderKey
+	doc render.Document
+}
+
+// renderCache keeps the most recently used rendered revisions. Entries never
+// go stale, an edited article is looked up under its new version.
+type renderCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[renderKey]*list.Element
+}
+
+func newRenderCache(size int) *renderCache {
+	return &renderCache{size: size, order: list.New(), items: make(map[renderKey]*list.Element)}
+}
+
+func (c *renderCache) get(key renderKey) (render.Document, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return render.Document{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*renderEntry).doc, true
+}
+
+func (c *renderCache) put(key renderKey, doc render.Document) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&renderEntry{key: key, doc: doc})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*renderEntry).key)
+	}
+}
+
+// Render returns a with its content rendered according to its content
+// format, reusing the result of an earlier call for the same version
+func (s *Service) Render(a Article) (RenderedArticle, error) {
+	key := renderKey{id: a.ID, version: a.Version}
+	doc, ok := s.renders.get(key)
+	if !ok {
+		var err error
+		if doc, err = render.Render(a.Content, a.ContentFormat); err != nil {
+			return RenderedArticle{}, err
+		}
+		s.renders.put(key, doc)
+	}
+	return RenderedArticle{Article: a, ContentHTML: doc.HTML, TOC: doc.TOC}, nil
+}
+
+// applyMetadata sets the word count, reading time and excerpt of a from its
+// rendered content, so markup is neither counted nor shown in the excerpt
+func applyMetadata(a *Article) error {
+	doc, err := render.Render(a.Content, a.ContentFormat)
+	if err != nil {
+		return err
+	}
+	a.WordCount, a.Excerpt = doc.Words, doc.Excerpt
+	// round up: any text takes at least a minute to read
+	a.ReadingTime = (doc.Words + WordsPerMinute - 1) / WordsPerMinute
+	return nil
+}
@@ -4,17 +4,213 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
 )
 
 type Repository interface {
-	Insert(ctx context.Context, title, content, category, status string) (Article, error)
-	List(ctx context.Context, limit, offset int, filter ListFilter) ([]Article, int64, error)
+	// WithinTx runs fn atomically. Repository calls made with the ctx passed to
+	// fn, including those of other repositories sharing the database, join the
+	// transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// Insert stores title, slug, content, category_id, author_id, status and tags of a.
+	// A slug already in use returns ErrSlugConflict.
+	Insert(ctx context.Context, a Article) (Article, error)
+	// List selects only the columns of q.Fields when set
+	List(ctx context.Context, q ListQuery) ([]Article, error)
+	// Export calls fn for every article matching filter in id order, reading
+	// rows while fn consumes them instead of loading the whole result. An
+	// error returned by fn stops the export and is returned.
+	Export(ctx context.Context, filter ListFilter, fn func(Article) error) error
+	// Search returns hits ordered by relevance; Highlights are left empty
+	Search(ctx context.Context, q SearchQuery) ([]SearchHit, error)
 	FindByID(ctx context.Context, id int64) (Article, error)
-	UpdateAll(ctx context.Context, id int64, title, content, category, status string) (Article, error)
+	// FindByIDForUpdate reads article id like FindByID and, inside WithinTx,
+	// locks it until the transaction ends
+	FindByIDForUpdate(ctx context.Context, id int64) (Article, error)
+	// FindByIDFields reads only the columns of fields (see ParseFields) and id;
+	// the tags are loaded when fields include them
+	FindByIDFields(ctx context.Context, id int64, fields []string) (Article, error)
+	FindBySlug(ctx context.Context, slug string) (Article, error)
+	FindByExternalID(ctx context.Context, externalID string) (Article, error)
+	// FindByOldSlug resolves a slug from the history to the article now owning it
+	FindByOldSlug(ctx context.Context, slug string) (Article, error)
+	// SlugTaken reports whether slug is used, currently or historically, by an article other than exceptID
+	SlugTaken(ctx context.Context, slug string, exceptID int64) (bool, error)
+	// UpdateAll overwrites every editable field of article a.ID. A non-zero
+	// expectedVersion makes the write conditional and returns ErrVersionMismatch
+	// when it is stale. A changed slug keeps the old one in the slug history.
+	// Tags are replaced by a.Tags.
+	UpdateAll(ctx context.Context, a Article, expectedVersion int64) (Article, error)
 	Delete(ctx context.Context, id int64) error
 	Count(ctx context.Context, filter ListFilter) (int64, error)
+	// CountByCategory counts every article in a category, trashed ones included
+	CountByCategory(ctx context.Context, categoryID int64) (int64, error)
+	Trash(ctx context.Context, id int64, actor string) (Article, error)
+	// Restore moves a trashed article back to draft
+	Restore(ctx context.Context, id int64) (Article, error)
+	// PurgeTrashed deletes articles trashed before the given time and returns
+	// them as they were
+	PurgeTrashed(ctx context.Context, before time.Time) ([]Article, error)
+	// PublishDue moves up to limit scheduled articles with publish_at <= now to
+	// publish and returns their ids. Concurrent callers never publish the same article.
+	PublishDue(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	ListRevisions(ctx context.Context, articleID int64) ([]Revision, error)
+	FindRevision(ctx context.Context, articleID int64, revision int) (Revision, error)
+	// ListTags returns every tag with the number of articles using it
+	ListTags(ctx context.Context) ([]Tag, error)
+	AddTransition(ctx context.Context, t TransitionRecord) (TransitionRecord, error)
+	// ListTransitions returns the workflow history of an article, oldest first
+	ListTransitions(ctx context.Context, articleID int64) ([]TransitionRecord, error)
+}
+
+// categoryNameExpr selects the category name of the current articles row
+const categoryNameExpr = `(SELECT c.name FROM categories c WHERE c.id = articles.category_id)`
+
+// articleSelect maps the JSON key of every Article column to the expression
+// selecting it, in scan order. Tags are loaded from article_tags instead.
+var articleSelect = []struct{ field, expr string }{
+	{"id", "id"}, {"title", "title"}, {"slug", "slug"}, {"external_id", "external_id"},
+	{"content", "content"}, {"content_format", "content_format"},
+	{"word_count", "word_count"}, {"reading_time", "reading_time"}, {"excerpt", "excerpt"},
+	{"category_id", "category_id"}, {"category", categoryNameExpr}, {"author_id", "author_id"},
+	{"status", "status"}, {"publish_at", "publish_at"}, {"version", "version"},
+	{"previous_status", "previous_status"}, {"trashed_at", "trashed_at"}, {"trashed_by", "trashed_by"},
+	{"created_at", "created_at"}, {"updated_at", "updated_at"},
+}
+
+// articleColumns selects every column of articleSelect
+var articleColumns = selectColumns(nil)
+
+// selectedFields returns the columns of articleSelect a projection on fields
+// reads, always including id; nil fields select every column
+func selectedFields(fields []string) []string {
+	res := make([]string, 0, len(articleSelect))
+	for _, c := range articleSelect {
+		if fields == nil || c.field == "id" || slices.Contains(fields, c.field) {
+			res = append(res, c.field)
+		}
+	}
+	return res
+}
+
+// selectColumns renders the select list of a projection on fields
+func selectColumns(fields []string) string {
+	selected := selectedFields(fields)
+	exprs := make([]string, 0, len(selected))
+	for _, c := range articleSelect {
+		if slices.Contains(selected, c.field) {
+			exprs = append(exprs, c.expr)
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// wantsTags reports whether a projection on fields includes the tags
+func wantsTags(fields []string) bool {
+	return fields == nil || slices.Contains(fields, "tags")
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// articleRow receives the nullable columns of one articles row
+type articleRow struct {
+	a                                 Article
+	externalID, prevStatus, trashedBy sql.NullString
+	trashedAt, publishAt              sql.NullTime
+	authorID                          sql.NullInt64
+}
+
+// dest returns the scan destination of the column selecting field
+func (r *articleRow) dest(field string) interface{} {
+	switch field {
+	case "id":
+		return &r.a.ID
+	case "title":
+		return &r.a.Title
+	case "slug":
+		return &r.a.Slug
+	case "external_id":
+		return &r.externalID
+	case "content":
+		return &r.a.Content
+	case "content_format":
+		return &r.a.ContentFormat
+	case "word_count":
+		return &r.a.WordCount
+	case "reading_time":
+		return &r.a.ReadingTime
+	case "excerpt":
+		return &r.a.Excerpt
+	case "category_id":
+		return &r.a.CategoryID
+	case "category":
+		return &r.a.Category
+	case "author_id":
+		return &r.authorID
+	case "status":
+		return &r.a.Status
+	case "publish_at":
+		return &r.publishAt
+	case "version":
+		return &r.a.Version
+	case "previous_status":
+		return &r.prevStatus
+	case "trashed_at":
+		return &r.trashedAt
+	case "trashed_by":
+		return &r.trashedBy
+	case "created_at":
+		return &r.a.CreatedAt
+	case "updated_at":
+		return &r.a.UpdatedAt
+	}
+	panic("article: no column for field " + field)
+}
+
+func (r *articleRow) article() Article {
+	a := r.a
+	a.ExternalID = r.externalID.String
+	a.PreviousStatus = r.prevStatus.String
+	a.TrashedBy = r.trashedBy.String
+	if r.authorID.Valid {
+		a.AuthorID = &r.authorID.Int64
+	}
+	if r.publishAt.Valid {
+		a.PublishAt = &r.publishAt.Time
+	}
+	if r.trashedAt.Valid {
+		a.TrashedAt = &r.trashedAt.Time
+	}
+	return a
+}
+
+// scanArticle scans articleColumns followed by any extra selected columns
+func scanArticle(s rowScanner, extra ...interface{}) (Article, error) {
+	return scanArticleFields(s, nil, extra...)
+}
+
+// scanArticleFields scans the columns of selectColumns(fields) followed by
+// any extra selected columns; fields that were not selected keep their zero value
+func scanArticleFields(s rowScanner, fields []string, extra ...interface{}) (Article, error) {
+	var row articleRow
+	selected := selectedFields(fields)
+	dest := make([]interface{}, 0, len(selected)+len(extra))
+	for _, f := range selected {
+		dest = append(dest, row.dest(f))
+	}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return Article{}, err
+	}
+	return row.article(), nil
 }
 
 type MySQLRepository struct {
@@ -25,88 +221,330 @@ func NewMySQLRepository(db *sql.DB) *MySQLRepository {
 	return &MySQLRepository{db: db}
 }
 
-func (r *MySQLRepository) Insert(ctx context.Context, title, content, category, status string) (Article, error) {
+// conn runs queries inside the transaction of WithinTx when ctx carries one
+func (r *MySQLRepository) conn(ctx context.Context) database.Querier {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *MySQLRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.WithTx(ctx, r.db, fn)
+}
+
+func (r *MySQLRepository) Insert(ctx context.Context, a Article) (Article, error) {
 	q := `
-    INSERT INTO articles (title, content, category, status)
-    VALUES (?, ?, ?, ?)
+    INSERT INTO articles (title, slug, external_id, content, content_format, word_count, reading_time, excerpt, category_id, author_id, status, publish_at)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `
-	res, err := r.db.ExecContext(ctx, q, title, content, category, status)
-	if err != nil {
-		return Article{}, err
-	}
-	id, err := res.LastInsertId()
+	var id int64
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		res, err := tx.ExecContext(ctx, q, a.Title, a.Slug, nullString(a.ExternalID), a.Content, a.ContentFormat, a.WordCount, a.ReadingTime, a.Excerpt, a.CategoryID, a.AuthorID, a.Status, a.PublishAt)
+		if err != nil {
+			return mapDuplicateKey(err)
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+		if err := syncTags(ctx, tx, id, a.Tags); err != nil {
+			return err
+		}
+		return insertRevision(ctx, tx, id)
+	})
 	if err != nil {
 		return Article{}, err
 	}
 	return r.FindByID(ctx, id)
 }
 
-func (r *MySQLRepository) List(ctx context.Context, limit, offset int, filter ListFilter) ([]Article, int64, error) {
-	base := `SELECT id, title, content, category, status, created_at, updated_at FROM articles`
-	where, args := buildFilterClause(filter)
-	q := base + where + " ORDER BY id LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
-	rows, err := r.db.QueryContext(ctx, q, args...)
+func (r *MySQLRepository) List(ctx context.Context, lq ListQuery) ([]Article, error) {
+	base := `SELECT ` + selectColumns(lq.Fields) + ` FROM articles`
+	where, args := buildFilterClause(lq.Filter)
+
+	var q string
+	if lq.Cursor != nil {
+		// keyset pagination: seek past the cursor instead of skipping rows
+		cond, keyArgs, err := keysetCondition(lq.Sort, *lq.Cursor)
+		if err != nil {
+			return []Article{}, err
+		}
+		where = appendCondition(where, cond)
+		args = append(args, keyArgs...)
+		q = base + where + orderByClause(lq.Sort, lq.Cursor.Backward) + " LIMIT ?"
+		args = append(args, lq.Limit)
+	} else {
+		q = base + where + orderByClause(lq.Sort, false) + " LIMIT ? OFFSET ?"
+		args = append(args, lq.Limit, lq.Offset)
+	}
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
 	if err != nil {
-		return []Article{}, 0, err
+		return []Article{}, err
 	}
 	defer rows.Close()
 
 	res := make([]Article, 0)
 	for rows.Next() {
-		var a Article
-		if errScan := rows.Scan(&a.ID, &a.Title, &a.Content, &a.Category, &a.Status, &a.CreatedAt, &a.UpdatedAt); errScan != nil {
-			return []Article{}, 0, errScan
+		a, errScan := scanArticleFields(rows, lq.Fields)
+		if errScan != nil {
+			return []Article{}, errScan
 		}
 		res = append(res, a)
 	}
 	if errRows := rows.Err(); errRows != nil {
-		return []Article{}, 0, errRows
+		return []Article{}, errRows
+	}
+	if !wantsTags(lq.Fields) {
+		return res, nil
 	}
-	count, err := r.Count(ctx, filter)
+	if err := r.attachTags(ctx, res); err != nil {
+		return []Article{}, err
+	}
+	return res, nil
+}
+
+// exportBatchSize is how many exported rows share one tag lookup
+const exportBatchSize = 500
+
+func (r *MySQLRepository) Export(ctx context.Context, filter ListFilter, fn func(Article) error) error {
+	where, args := buildFilterClause(filter)
+	rows, err := r.conn(ctx).QueryContext(ctx, `SELECT `+articleColumns+` FROM articles`+where+` ORDER BY id`, args...)
 	if err != nil {
-		return []Article{}, 0, err
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]Article, 0, exportBatchSize)
+	flush := func() error {
+		if err := r.attachTags(ctx, batch); err != nil {
+			return err
+		}
+		for _, a := range batch {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for rows.Next() {
+		a, errScan := scanArticle(rows)
+		if errScan != nil {
+			return errScan
+		}
+		if batch = append(batch, a); len(batch) == exportBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
-	return res, count, nil
+	return flush()
+}
+
+func (r *MySQLRepository) Search(ctx context.Context, sq SearchQuery) ([]SearchHit, error) {
+	match := `MATCH(title, content) AGAINST (? IN NATURAL LANGUAGE MODE)`
+	if sq.Mode == SearchModeBoolean {
+		match = `MATCH(title, content) AGAINST (? IN BOOLEAN MODE)`
+	}
+	where, args := buildFilterClause(sq.Filter)
+	where = appendCondition(where, match)
+
+	q := `SELECT ` + articleColumns + `, ` + match + ` AS score FROM articles` + where + ` ORDER BY score DESC, id LIMIT ? OFFSET ?`
+	args = append([]interface{}{sq.Query}, args...)
+	args = append(args, sq.Query, sq.Limit, sq.Offset)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return []SearchHit{}, mapSearchError(sq.Mode, err)
+	}
+	defer rows.Close()
+
+	res := make([]SearchHit, 0)
+	for rows.Next() {
+		var score float64
+		a, errScan := scanArticle(rows, &score)
+		if errScan != nil {
+			return []SearchHit{}, errScan
+		}
+		res = append(res, SearchHit{Article: a, Score: score})
+	}
+	if errRows := rows.Err(); errRows != nil {
+		return []SearchHit{}, mapSearchError(sq.Mode, errRows)
+	}
+	ids := make([]int64, len(res))
+	for i, h := range res {
+		ids[i] = h.ID
+	}
+	tags, err := r.loadTags(ctx, ids)
+	if err != nil {
+		return []SearchHit{}, err
+	}
+	for i := range res {
+		res[i].Tags = tagsOrEmpty(tags[res[i].ID])
+	}
+	return res, nil
 }
 
 func (r *MySQLRepository) FindByID(ctx context.Context, id int64) (Article, error) {
+	q := `SELECT ` + articleColumns + ` FROM articles WHERE id = ?`
+	return r.findOne(ctx, q, id)
+}
+
+func (r *MySQLRepository) FindByIDForUpdate(ctx context.Context, id int64) (Article, error) {
+	q := `SELECT ` + articleColumns + ` FROM articles WHERE id = ? FOR UPDATE OF articles`
+	return r.findOne(ctx, q, id)
+}
+
+func (r *MySQLRepository) FindByIDFields(ctx context.Context, id int64, fields []string) (Article, error) {
+	q := `SELECT ` + selectColumns(fields) + ` FROM articles WHERE id = ?`
+	return r.findOneFields(ctx, fields, q, id)
+}
+
+func (r *MySQLRepository) UpdateAll(ctx context.Context, a Article, expectedVersion int64) (Article, error) {
 	q := `
-    SELECT id, title, content, category, status, created_at, updated_at
-    FROM articles
-    WHERE id = ?
+    UPDATE articles
+    SET title = ?, slug = ?, external_id = ?, content = ?, content_format = ?, word_count = ?, reading_time = ?, excerpt = ?, category_id = ?, status = ?, publish_at = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND (? = 0 OR version = ?)
     `
-	var a Article
-	err := r.db.QueryRowContext(ctx, q, id).Scan(&a.ID, &a.Title, &a.Content, &a.Category, &a.Status, &a.CreatedAt, &a.UpdatedAt)
+	id := a.ID
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		// lock the row and remember the slug it had before this write
+		var oldSlug string
+		if err := tx.QueryRowContext(ctx, `SELECT slug FROM articles WHERE id = ? FOR UPDATE`, id).Scan(&oldSlug); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, q, a.Title, a.Slug, nullString(a.ExternalID), a.Content, a.ContentFormat, a.WordCount, a.ReadingTime, a.Excerpt, a.CategoryID, a.Status, a.PublishAt, id, expectedVersion, expectedVersion)
+		if err != nil {
+			return mapDuplicateKey(err)
+		}
+		n, err := res.RowsAffected()
+		if err == nil && n == 0 {
+			return r.notAffectedErr(ctx, id, ErrVersionMismatch)
+		}
+		if oldSlug != a.Slug {
+			if err := recordSlugChange(ctx, tx, id, oldSlug, a.Slug); err != nil {
+				return err
+			}
+		}
+		if err := syncTags(ctx, tx, id, a.Tags); err != nil {
+			return err
+		}
+		return insertRevision(ctx, tx, id)
+	})
+	if err != nil {
+		return Article{}, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MySQLRepository) FindBySlug(ctx context.Context, slug string) (Article, error) {
+	q := `SELECT ` + articleColumns + ` FROM articles WHERE slug = ?`
+	return r.findOne(ctx, q, slug)
+}
+
+func (r *MySQLRepository) FindByExternalID(ctx context.Context, externalID string) (Article, error) {
+	q := `SELECT ` + articleColumns + ` FROM articles WHERE external_id = ?`
+	return r.findOne(ctx, q, externalID)
+}
+
+// findOne runs a single-row article query and loads its tags
+func (r *MySQLRepository) findOne(ctx context.Context, q string, args ...interface{}) (Article, error) {
+	return r.findOneFields(ctx, nil, q, args...)
+}
+
+// findOneFields runs a single-row query selecting selectColumns(fields)
+func (r *MySQLRepository) findOneFields(ctx context.Context, fields []string, q string, args ...interface{}) (Article, error) {
+	a, err := scanArticleFields(r.conn(ctx).QueryRowContext(ctx, q, args...), fields)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Article{}, sql.ErrNoRows
 		}
 		return Article{}, err
 	}
-	return a, nil
+	if !wantsTags(fields) {
+		return a, nil
+	}
+	items := []Article{a}
+	if err := r.attachTags(ctx, items); err != nil {
+		return Article{}, err
+	}
+	return items[0], nil
 }
 
-func (r *MySQLRepository) UpdateAll(ctx context.Context, id int64, title, content, category, status string) (Article, error) {
-	q := `
-    UPDATE articles
-    SET title = ?, content = ?, category = ?, status = ?, updated_at = CURRENT_TIMESTAMP
-    WHERE id = ?
-    `
-	res, err := r.db.ExecContext(ctx, q, title, content, category, status, id)
+func (r *MySQLRepository) FindByOldSlug(ctx context.Context, slug string) (Article, error) {
+	var id int64
+	err := r.conn(ctx).QueryRowContext(ctx, `SELECT article_id FROM article_slugs WHERE slug = ?`, slug).Scan(&id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Article{}, sql.ErrNoRows
+		}
 		return Article{}, err
 	}
-	n, err := res.RowsAffected()
-	if err == nil && n == 0 {
-		return Article{}, sql.ErrNoRows
-	}
 	return r.FindByID(ctx, id)
 }
 
+func (r *MySQLRepository) SlugTaken(ctx context.Context, slug string, exceptID int64) (bool, error) {
+	q := `
+    SELECT EXISTS(SELECT 1 FROM articles WHERE slug = ? AND id <> ?)
+        OR EXISTS(SELECT 1 FROM article_slugs WHERE slug = ? AND article_id <> ?)
+    `
+	var taken bool
+	err := r.conn(ctx).QueryRowContext(ctx, q, slug, exceptID, slug, exceptID).Scan(&taken)
+	return taken, err
+}
+
+// recordSlugChange keeps oldSlug redirecting to the article and drops newSlug
+// from the history in case the article takes back one of its old slugs.
+func recordSlugChange(ctx context.Context, tx database.Querier, articleID int64, oldSlug, newSlug string) error {
+	q := `
+    INSERT INTO article_slugs (slug, article_id) VALUES (?, ?)
+    ON DUPLICATE KEY UPDATE article_id = VALUES(article_id), created_at = CURRENT_TIMESTAMP
+    `
+	if _, err := tx.ExecContext(ctx, q, oldSlug, articleID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM article_slugs WHERE slug = ? AND article_id = ?`, newSlug, articleID)
+	return err
+}
+
+// mapDuplicateKey turns a unique violation on the slug or external_id column
+// into ErrSlugConflict or ErrExternalIDConflict
+func mapDuplicateKey(err error) error {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) || myErr.Number != 1062 {
+		return err
+	}
+	switch {
+	case strings.Contains(myErr.Message, "external_id"):
+		return ErrExternalIDConflict
+	case strings.Contains(myErr.Message, "slug"):
+		return ErrSlugConflict
+	}
+	return err
+}
+
+// mapSearchError turns the syntax error MySQL reports for a malformed boolean
+// mode query, such as an unclosed quote, into ErrInvalidSearchQuery
+func mapSearchError(mode string, err error) error {
+	var myErr *mysql.MySQLError
+	if mode == SearchModeBoolean && errors.As(err, &myErr) && myErr.Number == 1064 {
+		return ErrInvalidSearchQuery
+	}
+	return err
+}
+
+// nullString stores an empty string as NULL, keeping unique indexes on optional columns usable
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
 	q := `DELETE FROM articles WHERE id = ?`
-	res, err := r.db.ExecContext(ctx, q, id)
+	res, err := r.conn(ctx).ExecContext(ctx, q, id)
 	if err != nil {
 		return err
 	}
@@ -117,42 +555,384 @@ func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
+func (r *MySQLRepository) CountByCategory(ctx context.Context, categoryID int64) (int64, error) {
+	var total int64
+	err := r.conn(ctx).QueryRowContext(ctx, `SELECT COUNT(*) FROM articles WHERE category_id = ?`, categoryID).Scan(&total)
+	return total, err
+}
+
 func (r *MySQLRepository) Count(ctx context.Context, filter ListFilter) (int64, error) {
 	base := `SELECT COUNT(*) FROM articles`
 	where, args := buildFilterClause(filter)
 	q := base + where
 	var total int64
-	err := r.db.QueryRowContext(ctx, q, args...).Scan(&total)
+	err := r.conn(ctx).QueryRowContext(ctx, q, args...).Scan(&total)
 	return total, err
 }
 
+// Trash moves an article to "thrash" and remembers its previous status for Restore
+func (r *MySQLRepository) Trash(ctx context.Context, id int64, actor string) (Article, error) {
+	q := `
+    UPDATE articles
+    SET previous_status = status, status = 'thrash', trashed_at = CURRENT_TIMESTAMP, trashed_by = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND status <> 'thrash'
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, actor, id)
+	if err != nil {
+		return Article{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return Article{}, r.notAffectedErr(ctx, id, ErrAlreadyTrashed)
+	}
+	return r.FindByID(ctx, id)
+}
+
+// Restore takes an article out of trash as a draft; previous_status is only
+// kept for reference while trashed
+func (r *MySQLRepository) Restore(ctx context.Context, id int64) (Article, error) {
+	q := `
+    UPDATE articles
+    SET status = 'draft', previous_status = NULL, trashed_at = NULL, trashed_by = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND status = 'thrash'
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, id)
+	if err != nil {
+		return Article{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return Article{}, r.notAffectedErr(ctx, id, ErrNotTrashed)
+	}
+	return r.FindByID(ctx, id)
+}
+
+// PurgeTrashed permanently deletes articles trashed before the given time and
+// returns them as they were before the delete
+func (r *MySQLRepository) PurgeTrashed(ctx context.Context, before time.Time) ([]Article, error) {
+	purged := make([]Article, 0)
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		q := `SELECT ` + articleColumns + ` FROM articles WHERE status = 'thrash' AND trashed_at IS NOT NULL AND trashed_at < ? FOR UPDATE`
+		rows, err := tx.QueryContext(ctx, q, before)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			a, errScan := scanArticle(rows)
+			if errScan != nil {
+				rows.Close()
+				return errScan
+			}
+			purged = append(purged, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(purged) == 0 {
+			return err
+		}
+		if err := r.attachTags(ctx, purged); err != nil {
+			return err
+		}
+
+		args := make([]interface{}, len(purged))
+		for i, a := range purged {
+			args[i] = a.ID
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM articles WHERE id IN (`+placeholders(len(args))+`)`, args...)
+		return err
+	})
+	if err != nil {
+		return []Article{}, err
+	}
+	return purged, nil
+}
+
+func (r *MySQLRepository) PublishDue(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		// SKIP LOCKED lets every replica run the scheduler: rows claimed by one
+		// transaction are skipped by the others instead of published twice
+		q := `
+        SELECT id FROM articles
+        WHERE status = 'scheduled' AND publish_at <= ?
+        ORDER BY publish_at, id
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED
+        `
+		rows, err := tx.QueryContext(ctx, q, now, limit)
+		if err != nil {
+			return err
+		}
+		args := make([]interface{}, 0)
+		for rows.Next() {
+			var id int64
+			if errScan := rows.Scan(&id); errScan != nil {
+				rows.Close()
+				return errScan
+			}
+			ids = append(ids, id)
+			args = append(args, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(ids) == 0 {
+			return err
+		}
+
+		up := `UPDATE articles SET status = 'publish', version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id IN (` + placeholders(len(ids)) + `)`
+		if _, err := tx.ExecContext(ctx, up, args...); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := insertRevision(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *MySQLRepository) ListRevisions(ctx context.Context, articleID int64) ([]Revision, error) {
+	q := `SELECT ` + revisionColumns + ` FROM article_revisions WHERE article_id = ? ORDER BY revision`
+	rows, err := r.conn(ctx).QueryContext(ctx, q, articleID)
+	if err != nil {
+		return []Revision{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Revision, 0)
+	for rows.Next() {
+		rev, errScan := scanRevision(rows)
+		if errScan != nil {
+			return []Revision{}, errScan
+		}
+		res = append(res, rev)
+	}
+	return res, rows.Err()
+}
+
+func (r *MySQLRepository) FindRevision(ctx context.Context, articleID int64, revision int) (Revision, error) {
+	q := `SELECT ` + revisionColumns + ` FROM article_revisions WHERE article_id = ? AND revision = ?`
+	rev, err := scanRevision(r.conn(ctx).QueryRowContext(ctx, q, articleID, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Revision{}, sql.ErrNoRows
+		}
+		return Revision{}, err
+	}
+	return rev, nil
+}
+
+func (r *MySQLRepository) AddTransition(ctx context.Context, t TransitionRecord) (TransitionRecord, error) {
+	q := `
+    INSERT INTO article_transitions (article_id, action, from_status, to_status, comment, actor)
+    VALUES (?, ?, ?, ?, NULLIF(?, ''), ?)
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, t.ArticleID, t.Action, t.From, t.To, t.Comment, t.Actor)
+	if err != nil {
+		return TransitionRecord{}, err
+	}
+	if t.ID, err = res.LastInsertId(); err != nil {
+		return TransitionRecord{}, err
+	}
+	t.CreatedAt = time.Now()
+	return t, nil
+}
+
+func (r *MySQLRepository) ListTransitions(ctx context.Context, articleID int64) ([]TransitionRecord, error) {
+	q := `
+    SELECT id, article_id, action, from_status, to_status, comment, actor, created_at
+    FROM article_transitions WHERE article_id = ? ORDER BY id
+    `
+	rows, err := r.conn(ctx).QueryContext(ctx, q, articleID)
+	if err != nil {
+		return []TransitionRecord{}, err
+	}
+	defer rows.Close()
+
+	res := make([]TransitionRecord, 0)
+	for rows.Next() {
+		var t TransitionRecord
+		var comment sql.NullString
+		if errScan := rows.Scan(&t.ID, &t.ArticleID, &t.Action, &t.From, &t.To, &comment, &t.Actor, &t.CreatedAt); errScan != nil {
+			return []TransitionRecord{}, errScan
+		}
+		t.Comment = comment.String
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+const revisionColumns = `id, article_id, revision, title, content, content_format, category, status, created_at`
+
+func scanRevision(s rowScanner) (Revision, error) {
+	var rev Revision
+	err := s.Scan(&rev.ID, &rev.ArticleID, &rev.Revision, &rev.Title, &rev.Content, &rev.ContentFormat, &rev.Category, &rev.Status, &rev.CreatedAt)
+	return rev, err
+}
+
+// insertRevision snapshots the current row of an article as its next revision.
+// Must run in the same transaction as the write it records. The revision
+// number is the version that write set on the article row, which it keeps
+// locked until commit, so concurrent writes cannot number two snapshots alike;
+// uq_article_revisions_article_revision rejects any that still would.
+func insertRevision(ctx context.Context, tx database.Querier, articleID int64) error {
+	q := `
+    INSERT INTO article_revisions (article_id, revision, title, content, content_format, category, status)
+    SELECT a.id, a.version,
+        a.title, a.content, a.content_format, (SELECT c.name FROM categories c WHERE c.id = a.category_id), a.status
+    FROM articles a
+    WHERE a.id = ?
+    `
+	_, err := tx.ExecContext(ctx, q, articleID)
+	return err
+}
+
+// notAffectedErr distinguishes a missing article from one whose state did not
+// match the UPDATE condition.
+func (r *MySQLRepository) notAffectedErr(ctx context.Context, id int64, stateErr error) error {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return stateErr
+}
+
+// orderByClause renders the (whitelisted) sort fields, reversed for backward scans
+func orderByClause(fields []SortField, reverse bool) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = sortColumn(f.Field)
+		if f.Desc != reverse {
+			parts[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// keysetCondition expands the row comparison (f1, f2, id) > (?, ?, ?) per
+// field so mixed ASC/DESC orderings work:
+// f1 > ? OR (f1 = ? AND f2 > ?) OR (f1 = ? AND f2 = ? AND id > ?)
+func keysetCondition(fields []SortField, cur Cursor) (string, []interface{}, error) {
+	key, err := keyValues(fields, cur)
+	if err != nil {
+		return "", nil, err
+	}
+	ors := make([]string, 0, len(fields))
+	args := make([]interface{}, 0)
+	for i, f := range fields {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, sortColumn(fields[j].Field)+" = ?")
+			args = append(args, key[j])
+		}
+		op := " > ?"
+		if f.Desc != cur.Backward {
+			op = " < ?"
+		}
+		ands = append(ands, sortColumn(f.Field)+op)
+		args = append(args, key[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args, nil
+}
+
+// sortColumn maps a sort field to its SQL expression. status is an ENUM,
+// which ORDER BY sorts by index but compares to the cursor as a string; the
+// cast makes both use the string, the order of the memory repository.
+func sortColumn(field string) string {
+	switch field {
+	case "category":
+		return categoryNameExpr
+	case "status":
+		return "CAST(status AS CHAR)"
+	}
+	return field
+}
+
+func appendCondition(where, cond string) string {
+	if where == "" {
+		return " WHERE " + cond
+	}
+	return where + " AND " + cond
+}
+
 // buildFilterClause to build WHERE clause and args based on ListFilter
 func buildFilterClause(filter ListFilter) (string, []interface{}) {
 	conds := make([]string, 0)
 	args := make([]interface{}, 0)
 
-	trimmedTitle := "%" + strings.TrimSpace(filter.Title) + "%"
-	if trimmedTitle != "" {
+	if title := strings.TrimSpace(filter.Title); title != "" {
 		conds = append(conds, "LOWER(title) LIKE ?")
-		args = append(args, trimmedTitle)
+		args = append(args, "%"+escapeLike(strings.ToLower(title))+"%")
+	}
+	if content := strings.TrimSpace(filter.Content); content != "" {
+		conds = append(conds, "LOWER(content) LIKE ?")
+		args = append(args, "%"+escapeLike(strings.ToLower(content))+"%")
 	}
 
-	trimmedCategory := filter.Category
-	if trimmedCategory != "" {
-		conds = append(conds, "category = ?")
-		args = append(args, trimmedCategory)
+	// category filters hold category slugs
+	const categoryIDs = "category_id %s (SELECT id FROM categories WHERE slug IN (%s))"
+	if len(filter.Categories) > 0 {
+		conds = append(conds, fmt.Sprintf(categoryIDs, "IN", placeholders(len(filter.Categories))))
+		for _, c := range filter.Categories {
+			args = append(args, c)
+		}
+	}
+	if len(filter.NotCategories) > 0 {
+		conds = append(conds, fmt.Sprintf(categoryIDs, "NOT IN", placeholders(len(filter.NotCategories))))
+		for _, c := range filter.NotCategories {
+			args = append(args, c)
+		}
 	}
+	conds, args = appendInCondition(conds, args, "status IN", filter.Statuses)
+	conds, args = appendInCondition(conds, args, "status NOT IN", filter.NotStatuses)
 
-	trimmedStatus := strings.TrimSpace(filter.Status)
-	if trimmedStatus != "" {
-		conds = append(conds, "status = ?")
-		args = append(args, trimmedStatus)
+	if len(filter.Tags) > 0 {
+		sub := `id IN (SELECT at.article_id FROM article_tags at JOIN tags t ON t.id = at.tag_id WHERE t.slug IN (` + placeholders(len(filter.Tags)) + `)`
+		for _, t := range filter.Tags {
+			args = append(args, t)
+		}
+		if filter.TagMatch == TagMatchAll {
+			sub += ` GROUP BY at.article_id HAVING COUNT(DISTINCT at.tag_id) = ?`
+			args = append(args, len(filter.Tags))
+		}
+		conds = append(conds, sub+")")
 	}
 
-	log.Printf("conds: %v, args: %v", conds, args)
+	if filter.CreatedFrom != nil {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, *filter.CreatedTo)
+	}
+	if filter.UpdatedSince != nil {
+		conds = append(conds, "updated_at >= ?")
+		args = append(args, *filter.UpdatedSince)
+	}
 
 	if len(conds) == 0 {
 		return "", args
 	}
 	return " WHERE " + strings.Join(conds, " AND "), args
 }
+
+// appendInCondition adds "<expr> (?, ?, ...)" when values is not empty
+func appendInCondition(conds []string, args []interface{}, expr string, values []string) ([]string, []interface{}) {
+	if len(values) == 0 {
+		return conds, args
+	}
+	conds = append(conds, expr+" ("+placeholders(len(values))+")")
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return conds, args
+}
+
+// escapeLike makes user input match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,52 @@
+package article
+
+import (
+	"context"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+)
+
+// scheduleBatchSize caps how many articles one transaction publishes
+const scheduleBatchSize = 100
+
+// Scheduler periodically publishes scheduled articles that are due.
+type Scheduler struct {
+	svc      *Service
+	interval time.Duration
+}
+
+func NewScheduler(svc *Service, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &Scheduler{svc: svc, interval: interval}
+}
+
+// Run blocks until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.publish(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) publish(ctx context.Context) {
+	n, err := s.svc.PublishDue(ctx, scheduleBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Log.WithError(err).Error("publish scheduled articles failed")
+		}
+		return
+	}
+	if n > 0 {
+		logger.Log.WithField("published", n).Info("scheduled articles published")
+	}
+}
@@ -0,0 +1,181 @@
+package article
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	SearchModeNatural = "natural"
+	SearchModeBoolean = "boolean"
+
+	snippetRadius = 80
+)
+
+// searchTerm is one word of a search query. Operators only matter in boolean mode.
+type searchTerm struct {
+	word     string
+	required bool
+	excluded bool
+	prefix   bool
+}
+
+// parseSearchTerms splits a query into lowercase terms, understanding the
+// boolean-mode operators + - and trailing * the way MySQL does.
+func parseSearchTerms(q string, boolean bool) []searchTerm {
+	terms := make([]searchTerm, 0)
+	for _, f := range strings.Fields(strings.ToLower(q)) {
+		var t searchTerm
+		if boolean {
+			switch f[0] {
+			case '+':
+				t.required = true
+			case '-':
+				t.excluded = true
+			}
+			t.prefix = strings.HasSuffix(f, "*")
+		}
+		t.word = strings.TrimFunc(f, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if t.word != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// scoreText is the in-memory stand-in for MATCH ... AGAINST relevance.
+// It returns 0 when the text does not satisfy the query.
+func scoreText(title, content string, terms []searchTerm) float64 {
+	words := append(tokenize(title), tokenize(content)...)
+	titleWords := len(tokenize(title))
+
+	var score float64
+	matchedOptional := false
+	hasRequired := false
+	for _, t := range terms {
+		var hits float64
+		for i, w := range words {
+			if w == t.word || (t.prefix && strings.HasPrefix(w, t.word)) {
+				// title hits weigh more, like a human would expect
+				if i < titleWords {
+					hits += 2
+				} else {
+					hits++
+				}
+			}
+		}
+		switch {
+		case t.excluded:
+			if hits > 0 {
+				return 0
+			}
+		case t.required:
+			hasRequired = true
+			if hits == 0 {
+				return 0
+			}
+		default:
+			if hits > 0 {
+				matchedOptional = true
+			}
+		}
+		score += hits
+	}
+	if !hasRequired && !matchedOptional {
+		return 0
+	}
+	return score
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlight HTML-escapes text and wraps every term occurrence in <mark>.
+// With radius > 0 only a window around the first hit is kept.
+func highlight(text string, terms []searchTerm, radius int) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// case folding changed byte offsets; fall back to matching on the original
+		lower = text
+	}
+
+	type span struct{ start, end int }
+	spans := make([]span, 0)
+	for i := 0; i < len(lower); {
+		best := -1
+		for _, t := range terms {
+			if t.excluded || !strings.HasPrefix(lower[i:], t.word) {
+				continue
+			}
+			if i > 0 && isWordByte(lower[i-1]) {
+				continue
+			}
+			end := i + len(t.word)
+			if t.prefix {
+				for end < len(lower) && isWordByte(lower[end]) {
+					end++
+				}
+			}
+			best = max(best, end)
+		}
+		if best > 0 {
+			spans = append(spans, span{i, best})
+			i = best
+			continue
+		}
+		i++
+	}
+
+	from, to := 0, len(text)
+	if radius > 0 {
+		if len(spans) > 0 {
+			from = max(spans[0].start-radius, 0)
+			to = min(spans[0].end+radius, len(text))
+		} else {
+			to = min(2*radius, len(text))
+		}
+		// keep the window on rune boundaries
+		for from > 0 && !isRuneStart(text[from]) {
+			from--
+		}
+		for to < len(text) && !isRuneStart(text[to]) {
+			to++
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, sp := range spans {
+		if sp.end <= from || sp.start >= to {
+			continue
+		}
+		start, end := max(sp.start, from), min(sp.end, to)
+		b.WriteString(html.EscapeString(text[pos:start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[start:end]))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
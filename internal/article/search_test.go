@@ -0,0 +1,60 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+)
+
+func TestMapSearchError(t *testing.T) {
+	syntax := &mysql.MySQLError{Number: 1064, Message: "syntax error, unexpected $end"}
+	other := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	cases := []struct {
+		mode string
+		err  error
+		want error
+	}{
+		{SearchModeBoolean, syntax, ErrInvalidSearchQuery},
+		{SearchModeBoolean, other, other},
+		// natural language queries are never parsed, a 1064 there is a bug
+		{SearchModeNatural, syntax, syntax},
+	}
+	for _, tc := range cases {
+		if got := mapSearchError(tc.mode, tc.err); !errors.Is(got, tc.want) {
+			t.Errorf("%s %v = %v, want %v", tc.mode, tc.err, got, tc.want)
+		}
+	}
+}
+
+// searchErrRepository fails every search with err
+type searchErrRepository struct {
+	*txRepository
+	err error
+}
+
+func (r searchErrRepository) Search(ctx context.Context, sq SearchQuery) ([]SearchHit, error) {
+	return []SearchHit{}, r.err
+}
+
+func TestSearchRejectsMalformedBooleanQuery(t *testing.T) {
+	env := newTestEnv(t)
+	repo := searchErrRepository{txRepository: env.repo, err: ErrInvalidSearchQuery}
+	env.svc = NewService(repo, category.NewMemoryRepository(), env.audits, env.outbox, "test-secret")
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleReader})
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/articles/search?mode=boolean&q="+url.QueryEscape(`+"`), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want 400", res.StatusCode)
+	}
+}
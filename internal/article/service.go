@@ -2,77 +2,615 @@ package article
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/diff"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/render"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
 )
 
+// AuditLog stores the audit events of article mutations. Insert must join
+// the transaction of Repository.WithinTx carried by ctx.
+type AuditLog interface {
+	Insert(ctx context.Context, e audit.Event) (audit.Event, error)
+}
+
 type Service struct {
-	repo Repository
+	repo       Repository
+	categories CategoryLookup
+	audits     AuditLog
+	outbox     Outbox
+	cursors    cursorCodec
+	changes    *Broker
+	renders    *renderCache
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+// NewService creates the article service. cursorSecret signs pagination
+// cursors; when empty a random per-process secret is used.
+func NewService(repo Repository, categories CategoryLookup, audits AuditLog, outbox Outbox, cursorSecret string) *Service {
+	return &Service{repo: repo, categories: categories, audits: audits, outbox: outbox, cursors: newCursorCodec(cursorSecret), changes: NewBroker(), renders: newRenderCache(renderCacheSize)}
 }
 
-func (s *Service) Create(ctx context.Context, req CreateArticleRequest) (Article, error) {
+// Create stores a new article written by authorID, 0 when not written by a user
+func (s *Service) Create(ctx context.Context, req CreateArticleRequest, authorID int64) (Article, error) {
+	return s.create(ctx, req, authorID, "")
+}
+
+// create stores a new article under slug sl, or under a slug derived from
+// the title when sl is empty
+func (s *Service) create(ctx context.Context, req CreateArticleRequest, authorID int64, sl string) (Article, error) {
+	if !isEntryStatus(req.Status) {
+		return Article{}, ErrInvalidEntryStatus
+	}
+	cat, err := s.resolveCategory(ctx, req.Category)
+	if err != nil {
+		return Article{}, err
+	}
 	// create article
-	return s.repo.Insert(ctx, req.Title, req.Content, req.Category, req.Status)
+	a := Article{Title: req.Title, Content: req.Content, ContentFormat: req.ContentFormat, CategoryID: cat.ID, Category: cat.Name, Status: req.Status, Tags: req.Tags, ExternalID: req.ExternalID}
+	if a.ContentFormat == "" {
+		a.ContentFormat = render.FormatPlain
+	}
+	if err := applyMetadata(&a); err != nil {
+		return Article{}, err
+	}
+	if authorID != 0 {
+		a.AuthorID = &authorID
+	}
+	if err := applySchedule(&a, "", req.PublishAt); err != nil {
+		return Article{}, err
+	}
+	insert := func(a Article) (Article, error) {
+		return s.mutate(ctx, "create", 0, func(ctx context.Context) (Article, error) {
+			return s.repo.Insert(ctx, a)
+		})
+	}
+	if sl != "" {
+		a.Slug = sl
+		return insert(a)
+	}
+	return s.writeWithSlug(ctx, a, insert)
 }
 
-func (s *Service) List(ctx context.Context, limit, page int, filter ListFilter) ([]Article, response.Meta, error) {
-	newLimit := limit
+// List returns one page of articles. A cursor selects keyset pagination,
+// otherwise page/limit offset pagination is used.
+func (s *Service) List(ctx context.Context, p ListParams) ([]Article, response.Meta, error) {
+	newLimit := p.Limit
 	if newLimit <= 0 {
 		newLimit = 10
 	}
-	newPage := max(page, 1)
 
-	// calculate offset
-	offset := (newPage - 1) * newLimit
-	items, total, err := s.repo.List(ctx, newLimit, offset, filter)
+	// fetch one extra row to know whether another page exists
+	q := ListQuery{Filter: p.Filter, Sort: normalizeSort(p.Sort), Limit: newLimit + 1, Fields: p.Fields}
+	newPage := max(p.Page, 1)
+	if p.Cursor != "" {
+		cur, err := s.cursors.decode(p.Cursor)
+		if err != nil {
+			return []Article{}, response.Meta{}, err
+		}
+		// a cursor only makes sense for the ordering it was issued for
+		if cur.Sort != "" {
+			cursorSort, _ := ParseSort(cur.Sort)
+			cursorSort = normalizeSort(cursorSort)
+			if len(p.Sort) > 0 && sortSpec(q.Sort) != cur.Sort {
+				return []Article{}, response.Meta{}, ErrInvalidCursor
+			}
+			q.Sort = cursorSort
+		}
+		q.Cursor = &cur
+	} else {
+		// calculate offset
+		q.Offset = (newPage - 1) * newLimit
+	}
+
+	if q.Fields != nil {
+		// the cursors are built from the sort fields of the first and last item
+		q.Fields = slices.Clone(q.Fields)
+		for _, f := range q.Sort {
+			if !slices.Contains(q.Fields, f.Field) {
+				q.Fields = append(q.Fields, f.Field)
+			}
+		}
+	}
+
+	items, err := s.repo.List(ctx, q)
 	if err != nil {
 		return []Article{}, response.Meta{}, err
 	}
+	hasMore := len(items) > newLimit
+	if hasMore {
+		items = items[:newLimit]
+	}
+
+	meta := response.Meta{Limit: newLimit}
+	var hasPrev bool
+	switch {
+	case q.Cursor == nil:
+		meta.Page = newPage
+		meta.HasNext, hasPrev = hasMore, q.Offset > 0
+	case q.Cursor.Backward:
+		slices.Reverse(items)
+		meta.HasNext, hasPrev = true, hasMore
+	default:
+		meta.HasNext, hasPrev = hasMore, true
+	}
+
+	// cursors are also issued in offset mode so clients can switch over
+	if len(items) > 0 {
+		if meta.HasNext {
+			meta.NextCursor = s.cursors.encode(cursorFor(items[len(items)-1], q.Sort, false))
+		}
+		if hasPrev {
+			meta.PrevCursor = s.cursors.encode(cursorFor(items[0], q.Sort, true))
+		}
+	}
+
+	if p.IncludeTotal {
+		total, err := s.repo.Count(ctx, p.Filter)
+		if err != nil {
+			return []Article{}, response.Meta{}, err
+		}
+		meta.Total = &total
+	}
+
+	return items, meta, nil
+}
+
+// Search runs a full-text query and attaches highlighted snippets to each hit
+func (s *Service) Search(ctx context.Context, q SearchQuery, page int) ([]SearchHit, response.Meta, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	newPage := max(page, 1)
+	q.Limit = limit + 1
+	q.Offset = (newPage - 1) * limit
+
+	hits, err := s.repo.Search(ctx, q)
+	if err != nil {
+		return []SearchHit{}, response.Meta{}, err
+	}
+	hasNext := len(hits) > limit
+	if hasNext {
+		hits = hits[:limit]
+	}
+
+	terms := parseSearchTerms(q.Query, q.Mode == SearchModeBoolean)
+	for i := range hits {
+		hits[i].Highlights = Highlights{
+			Title:   highlight(hits[i].Title, terms, 0),
+			Content: highlight(hits[i].Content, terms, snippetRadius),
+		}
+	}
 
-	return items, *response.PageMeta(newLimit, offset, total), nil
+	return hits, response.Meta{Limit: limit, Page: newPage, HasNext: hasNext}, nil
 }
 
 func (s *Service) GetByID(ctx context.Context, id int64) (Article, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *Service) Update(ctx context.Context, id int64, req UpdateArticleRequest) (Article, error) {
+// GetByIDFields reads only fields of article id, nil reads every field
+func (s *Service) GetByIDFields(ctx context.Context, id int64, fields []string) (Article, error) {
+	if fields == nil {
+		return s.repo.FindByID(ctx, id)
+	}
+	return s.repo.FindByIDFields(ctx, id, fields)
+}
+
+// Update applies a partial update. expectedVersion (from If-Match) is optional;
+// the write is always conditional on the version that was read so concurrent
+// updates cannot overwrite each other. A status change must be allowed by
+// the Workflow and is recorded in the transition history.
+func (s *Service) Update(ctx context.Context, id int64, req UpdateArticleRequest, expectedVersion int64, actor string) (Article, error) {
 	// get current article
 	curr, err := s.repo.FindByID(ctx, id)
 	// check if article exists
 	if err != nil {
 		return Article{}, err
 	}
+	if expectedVersion != 0 && curr.Version != expectedVersion {
+		return Article{}, ErrVersionMismatch
+	}
 
+	up := curr
 	if req.Title != "" {
-		curr.Title = req.Title
+		up.Title = req.Title
 	}
 	if req.Content != "" {
-		curr.Content = req.Content
+		up.Content = req.Content
+	}
+	if req.ContentFormat != "" {
+		up.ContentFormat = req.ContentFormat
 	}
 	if req.Category != "" {
-		curr.Category = req.Category
+		cat, err := s.resolveCategory(ctx, req.Category)
+		if err != nil {
+			return Article{}, err
+		}
+		up.CategoryID, up.Category = cat.ID, cat.Name
+	}
+	var t Transition
+	if req.Status != "" && req.Status != curr.Status {
+		if t, err = checkStatusChange(curr.Status, req.Status, req.Comment); err != nil {
+			return Article{}, err
+		}
+		up.Status = req.Status
+	}
+	if req.Tags != nil {
+		up.Tags = req.Tags
+	}
+	if req.ExternalID != "" {
+		up.ExternalID = req.ExternalID
 	}
-	if req.Status != "" {
-		curr.Status = req.Status
+	if err := applySchedule(&up, curr.Status, req.PublishAt); err != nil {
+		return Article{}, err
 	}
 
 	// update article
-	up := UpdateArticleRequest{
-		Title:    curr.Title,
-		Content:  curr.Content,
-		Category: curr.Category,
-		Status:   curr.Status,
+	return retrySlugConflict(func() (Article, error) {
+		return s.mutate(ctx, "update", id, func(ctx context.Context) (Article, error) {
+			art, err := s.save(ctx, curr, up, curr.Version)
+			if err != nil {
+				return Article{}, err
+			}
+			return art, s.recordTransition(ctx, art.ID, t, req.Comment, actor)
+		})
+	})
+}
+
+// save writes up over curr, regenerating the slug only when the title
+// changed. It runs inside the transaction of mutate, so callers retry the
+// whole mutate with retrySlugConflict.
+func (s *Service) save(ctx context.Context, curr, up Article, expectedVersion int64) (Article, error) {
+	if err := applyMetadata(&up); err != nil {
+		return Article{}, err
+	}
+	if up.Title != curr.Title {
+		sl, err := s.uniqueSlug(ctx, up.Title, up.ID)
+		if err != nil {
+			return Article{}, err
+		}
+		up.Slug = sl
+	}
+	return s.repo.UpdateAll(ctx, up, expectedVersion)
+}
+
+func (s *Service) ListTags(ctx context.Context) ([]Tag, error) {
+	return s.repo.ListTags(ctx)
+}
+
+func (s *Service) ListRevisions(ctx context.Context, id int64) ([]Revision, error) {
+	// make sure the article exists so unknown ids return 404 instead of an empty list
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return []Revision{}, err
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+func (s *Service) GetRevision(ctx context.Context, id int64, rev int) (Revision, error) {
+	return s.repo.FindRevision(ctx, id, rev)
+}
+
+// DiffRevisions compares revision from against revision to of the same article
+func (s *Service) DiffRevisions(ctx context.Context, id int64, from, to int) (RevisionDiff, error) {
+	a, err := s.repo.FindRevision(ctx, id, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	b, err := s.repo.FindRevision(ctx, id, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+
+	fields := make(map[string]FieldChange)
+	if a.Title != b.Title {
+		fields["title"] = FieldChange{From: a.Title, To: b.Title}
+	}
+	if a.ContentFormat != b.ContentFormat {
+		fields["content_format"] = FieldChange{From: a.ContentFormat, To: b.ContentFormat}
 	}
+	if a.Category != b.Category {
+		fields["category"] = FieldChange{From: a.Category, To: b.Category}
+	}
+	if a.Status != b.Status {
+		fields["status"] = FieldChange{From: a.Status, To: b.Status}
+	}
+
+	return RevisionDiff{
+		From:    from,
+		To:      to,
+		Fields:  fields,
+		Content: diff.Lines(a.Content, b.Content),
+	}, nil
+}
+
+// RestoreRevision rolls an article back to the values of an older revision.
+// The rollback itself is recorded as a new revision; going back to the
+// revision's status must be allowed by the Workflow.
+func (s *Service) RestoreRevision(ctx context.Context, id int64, rev int, actor string) (Article, error) {
+	r, err := s.repo.FindRevision(ctx, id, rev)
+	if err != nil {
+		return Article{}, err
+	}
+	curr, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return Article{}, err
+	}
+	// revisions keep the category name; it must still be a managed category
+	cat, err := s.resolveCategory(ctx, r.Category)
+	if err != nil {
+		return Article{}, err
+	}
+	var t Transition
+	if r.Status != curr.Status {
+		if t, err = checkStatusChange(curr.Status, r.Status, ""); err != nil {
+			return Article{}, err
+		}
+	}
+	up := curr
+	up.Title, up.Content, up.ContentFormat, up.Status = r.Title, r.Content, r.ContentFormat, r.Status
+	up.CategoryID, up.Category = cat.ID, cat.Name
+	if err := applySchedule(&up, curr.Status, nil); err != nil {
+		return Article{}, err
+	}
+	return retrySlugConflict(func() (Article, error) {
+		return s.mutate(ctx, "restore_revision", id, func(ctx context.Context) (Article, error) {
+			art, err := s.save(ctx, curr, up, curr.Version)
+			if err != nil {
+				return Article{}, err
+			}
+			return art, s.recordTransition(ctx, art.ID, t, "", actor)
+		})
+	})
+}
+
+// Trash soft-deletes an article by moving it to the "thrash" status
+func (s *Service) Trash(ctx context.Context, id int64, actor string) (Article, error) {
+	return s.Transition(ctx, id, TransitionTrash, TransitionRequest{}, actor)
+}
+
+// Restore takes an article out of trash; it comes back as a draft
+func (s *Service) Restore(ctx context.Context, id int64, actor string) (Article, error) {
+	return s.Transition(ctx, id, TransitionRestore, TransitionRequest{}, actor)
+}
+
+// Transition performs workflow action on article id and records it in the
+// transition history
+func (s *Service) Transition(ctx context.Context, id int64, action string, req TransitionRequest, actor string) (Article, error) {
+	curr, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return Article{}, err
+	}
+	t, ok := FindTransition(curr.Status, action)
+	if !ok {
+		return Article{}, &TransitionError{From: curr.Status, Action: action, Allowed: NextTransitions(curr.Status)}
+	}
+	if t.RequireComment && strings.TrimSpace(req.Comment) == "" {
+		return Article{}, ErrCommentRequired
+	}
+
+	up := curr
+	up.Status = t.To
+	if err := applySchedule(&up, curr.Status, req.PublishAt); err != nil {
+		return Article{}, err
+	}
+	return s.mutate(ctx, t.Action, id, func(ctx context.Context) (Article, error) {
+		var art Article
+		var err error
+		switch t.Action {
+		case TransitionTrash:
+			art, err = s.repo.Trash(ctx, id, actor)
+		case TransitionRestore:
+			art, err = s.repo.Restore(ctx, id)
+		default:
+			art, err = s.repo.UpdateAll(ctx, up, curr.Version)
+		}
+		if err != nil {
+			return Article{}, err
+		}
+		return art, s.recordTransition(ctx, art.ID, t, req.Comment, actor)
+	})
+}
 
-	return s.repo.UpdateAll(ctx, id, up.Title, up.Content, up.Category, up.Status)
+func (s *Service) ListTransitions(ctx context.Context, id int64) ([]TransitionRecord, error) {
+	// make sure the article exists so unknown ids return 404 instead of an empty list
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return []TransitionRecord{}, err
+	}
+	return s.repo.ListTransitions(ctx, id)
+}
+
+// checkStatusChange finds the transition an update from one status to
+// another performs. Trash and restore are left to their own operations.
+func checkStatusChange(from, to, comment string) (Transition, error) {
+	t, ok := transitionTo(from, to)
+	if !ok || t.From == StatusThrash || t.To == StatusThrash {
+		return Transition{}, &TransitionError{From: from, To: to, Allowed: updatable(NextTransitions(from))}
+	}
+	if t.RequireComment && strings.TrimSpace(comment) == "" {
+		return Transition{}, ErrCommentRequired
+	}
+	return t, nil
+}
+
+// recordTransition appends t, if any, to the history of article id
+func (s *Service) recordTransition(ctx context.Context, id int64, t Transition, comment, actor string) error {
+	if t.Action == "" {
+		return nil
+	}
+	rec := TransitionRecord{ArticleID: id, Action: t.Action, From: t.From, To: t.To, Comment: strings.TrimSpace(comment), Actor: actor}
+	_, err := s.repo.AddTransition(ctx, rec)
+	return err
+}
+
+// mutate runs write and records its audit event and webhook events in one
+// transaction. Article id, 0 for creates, is read and locked inside the
+// transaction first, so the recorded before is the state write replaced.
+func (s *Service) mutate(ctx context.Context, action string, id int64, write func(ctx context.Context) (Article, error)) (Article, error) {
+	var art Article
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		var before *Article
+		if id != 0 {
+			curr, err := s.repo.FindByIDForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			before = &curr
+		}
+		var err error
+		if art, err = write(ctx); err != nil {
+			return err
+		}
+		return s.record(ctx, action, art.ID, before, &art)
+	})
+	if err != nil {
+		return Article{}, err
+	}
+	return art, nil
+}
+
+// record stores the audit event and the webhook events of a change to article
+// id and queues its stream event until the transaction commits
+func (s *Service) record(ctx context.Context, action string, id int64, before, after *Article) error {
+	if err := s.audit(ctx, action, id, before, after); err != nil {
+		return err
+	}
+	if err := s.enqueueEvents(ctx, id, before, after); err != nil {
+		return err
+	}
+	queueChange(ctx, before, after)
+	return nil
 }
 
+// audit stores an event for article id attributed to the audit.Source of ctx
+func (s *Service) audit(ctx context.Context, action string, id int64, before, after *Article) error {
+	src := audit.SourceFrom(ctx)
+	e := audit.Event{Actor: src.Actor, Action: action, ArticleID: id, RequestID: src.RequestID, IP: src.IP}
+	var err error
+	if before != nil {
+		if e.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if e.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	_, err = s.audits.Insert(ctx, e)
+	return err
+}
+
+// Delete permanently removes an article
 func (s *Service) Delete(ctx context.Context, id int64) error {
-	// delete article
-	return s.repo.Delete(ctx, id)
+	return s.withinTx(ctx, func(ctx context.Context) error {
+		// the audit log keeps the article as it was deleted
+		curr, err := s.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.record(ctx, "delete", id, &curr, nil)
+	})
+}
+
+// applySchedule checks publish_at against the status a write ends up with.
+// publishAt is the value sent by the client, nil when absent. Leaving the
+// scheduled status drops publish_at.
+func applySchedule(a *Article, prevStatus string, publishAt *time.Time) error {
+	switch {
+	case a.Status == StatusScheduled:
+		if publishAt != nil {
+			t := publishAt.Truncate(time.Second)
+			a.PublishAt = &t
+		}
+		if a.PublishAt == nil {
+			return ErrInvalidSchedule
+		}
+		// an unchanged schedule may be due already, the scheduler will pick it up
+		if (publishAt != nil || prevStatus != StatusScheduled) && !a.PublishAt.After(time.Now()) {
+			return ErrInvalidSchedule
+		}
+	case publishAt != nil:
+		return ErrInvalidSchedule
+	case a.Status != prevStatus:
+		a.PublishAt = nil
+	}
+	return nil
+}
+
+// schedulerActor is recorded as the actor of transitions made by the Scheduler
+const schedulerActor = "scheduler"
+
+var scheduledPublish, _ = FindTransition(StatusScheduled, "publish")
+
+// PublishDue publishes scheduled articles whose publish_at has passed, in
+// batches, and returns how many were published.
+func (s *Service) PublishDue(ctx context.Context, batchSize int) (int, error) {
+	ctx = audit.WithSource(ctx, audit.Source{Actor: schedulerActor})
+	total := 0
+	for {
+		var ids []int64
+		err := s.withinTx(ctx, func(ctx context.Context) error {
+			var err error
+			if ids, err = s.repo.PublishDue(ctx, time.Now(), batchSize); err != nil {
+				return err
+			}
+			for _, id := range ids {
+				art, err := s.repo.FindByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				if err := s.recordTransition(ctx, id, scheduledPublish, "", schedulerActor); err != nil {
+					return err
+				}
+				// the scheduler only changed status and version
+				before := art
+				before.Status, before.Version = StatusScheduled, art.Version-1
+				if err := s.record(ctx, scheduledPublish.Action, id, &before, &art); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += len(ids)
+		if len(ids) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// purgerActor is recorded as the actor of deletes made by the Purger
+const purgerActor = "purger"
+
+// PurgeTrashed permanently removes articles that have been in trash longer than retention
+func (s *Service) PurgeTrashed(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx = audit.WithSource(ctx, audit.Source{Actor: purgerActor})
+	var purged []Article
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = s.repo.PurgeTrashed(ctx, time.Now().Add(-retention)); err != nil {
+			return err
+		}
+		for i := range purged {
+			if err := s.record(ctx, "purge", purged[i].ID, &purged[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
 }
@@ -0,0 +1,271 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
+)
+
+type testTxKey struct{}
+
+func inTestTx(ctx context.Context) bool {
+	return ctx.Value(testTxKey{}) != nil
+}
+
+// txRepository stands in for the MySQL transaction: WithinTx marks ctx,
+// rolls back through MemoryRepository.WithinTx and counts how the outermost
+// call ended, committed or rolled back
+type txRepository struct {
+	*MemoryRepository
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (r *txRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if inTestTx(ctx) {
+		return fn(ctx)
+	}
+	err := r.MemoryRepository.WithinTx(ctx, func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, testTxKey{}, true))
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.rollbacks++
+	} else {
+		r.commits++
+	}
+	return err
+}
+
+func (r *txRepository) outcomes() (commits, rollbacks int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commits, r.rollbacks
+}
+
+// txAudit records the audit events and whether they joined the transaction
+type txAudit struct {
+	mu        sync.Mutex
+	events    []audit.Event
+	outsideTx int
+}
+
+func (a *txAudit) Insert(ctx context.Context, e audit.Event) (audit.Event, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !inTestTx(ctx) {
+		a.outsideTx++
+	}
+	a.events = append(a.events, e)
+	return e, nil
+}
+
+// txOutbox records the webhook events and whether they joined the
+// transaction; fail makes AddEvent return an error
+type txOutbox struct {
+	mu        sync.Mutex
+	events    []webhook.Event
+	outsideTx int
+	fail      error
+}
+
+func (o *txOutbox) AddEvent(ctx context.Context, e webhook.Event) (webhook.Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !inTestTx(ctx) {
+		o.outsideTx++
+	}
+	if o.fail != nil {
+		return webhook.Event{}, o.fail
+	}
+	o.events = append(o.events, e)
+	return e, nil
+}
+
+func (o *txOutbox) types() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	res := make([]string, len(o.events))
+	for i, e := range o.events {
+		res[i] = e.Type
+	}
+	return res
+}
+
+// testEnv is a Service on the memory repositories, with the categories
+// "Tech" and "News"
+type testEnv struct {
+	svc    *Service
+	repo   *txRepository
+	audits *txAudit
+	outbox *txOutbox
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	categories := category.NewMemoryRepository()
+	for _, name := range []string{"Tech", "News"} {
+		if _, err := categories.Insert(ctx, category.Category{Name: name, Slug: strings.ToLower(name)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := &testEnv{
+		repo:   &txRepository{MemoryRepository: NewMemoryRepository(categories)},
+		audits: &txAudit{},
+		outbox: &txOutbox{},
+	}
+	env.svc = NewService(env.repo, categories, env.audits, env.outbox, "test-secret")
+	return env
+}
+
+// validRequest returns a draft passing the CreateArticleRequest rules
+func validRequest(title string) CreateArticleRequest {
+	return CreateArticleRequest{
+		Title:    title + strings.Repeat(".", max(0, 20-len(title))),
+		Content:  strings.Repeat("lorem ipsum ", 20),
+		Category: "Tech",
+		Status:   StatusDraft,
+	}
+}
+
+func (env *testEnv) create(t *testing.T, title string) Article {
+	t.Helper()
+	a, err := env.svc.Create(context.Background(), validRequest(title), 0)
+	if err != nil {
+		t.Fatalf("create %q: %v", title, err)
+	}
+	return a
+}
+
+// drain returns the events already sent to sub
+func drain(sub *Subscription) []ChangeEvent {
+	res := make([]ChangeEvent, 0)
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return res
+			}
+			res = append(res, e)
+		case <-time.After(10 * time.Millisecond):
+			return res
+		}
+	}
+}
+
+func TestMutationsWriteOutboxInTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	sub, _, _ := env.svc.Changes().Subscribe(StreamFilter{}, 0)
+	defer env.svc.Changes().Unsubscribe(sub)
+
+	a := env.create(t, "Outbox article")
+	for _, action := range []string{"submit", "approve", TransitionTrash, TransitionRestore} {
+		if _, err := env.svc.Transition(ctx, a.ID, action, TransitionRequest{}, "tester"); err != nil {
+			t.Fatalf("%s: %v", action, err)
+		}
+	}
+	if err := env.svc.Delete(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	// receivers saw the trashed article go, deleting it for good says nothing new
+	b := env.create(t, "Trashed outbox article")
+	if _, err := env.svc.Trash(ctx, b.ID, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.svc.Delete(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		webhook.EventArticleCreated,
+		webhook.EventArticleUpdated,
+		webhook.EventArticleUpdated, webhook.EventArticlePublished,
+		webhook.EventArticleDeleted,
+		webhook.EventArticleCreated,
+		webhook.EventArticleDeleted,
+		webhook.EventArticleCreated,
+		webhook.EventArticleDeleted,
+	}
+	if got := env.outbox.types(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("outbox events = %v, want %v", got, want)
+	}
+	if len(env.audits.events) != 9 {
+		t.Errorf("audit events = %d, want 9", len(env.audits.events))
+	}
+	if env.outbox.outsideTx != 0 || env.audits.outsideTx != 0 {
+		t.Errorf("written outside the transaction: %d outbox, %d audit events", env.outbox.outsideTx, env.audits.outsideTx)
+	}
+	// the audit log keeps the article as the delete found it
+	if del := env.audits.events[5]; del.Action != "delete" || !strings.Contains(string(del.Before), `"status":"draft"`) {
+		t.Errorf("delete audit event = %s %s", del.Action, del.Before)
+	}
+	if commits, rollbacks := env.repo.outcomes(); commits != 9 || rollbacks != 0 {
+		t.Errorf("transactions = %d committed, %d rolled back", commits, rollbacks)
+	}
+	if got := drain(sub); len(got) != 9 {
+		t.Errorf("stream events = %d, want 9", len(got))
+	}
+}
+
+func TestFailedOutboxRollsBackMutation(t *testing.T) {
+	env := newTestEnv(t)
+	sub, _, _ := env.svc.Changes().Subscribe(StreamFilter{}, 0)
+	defer env.svc.Changes().Unsubscribe(sub)
+
+	errOutbox := errors.New("outbox unavailable")
+	env.outbox.fail = errOutbox
+	if _, err := env.svc.Create(context.Background(), validRequest("Rolled back article"), 0); !errors.Is(err, errOutbox) {
+		t.Fatalf("Create error = %v, want the outbox error", err)
+	}
+	if commits, rollbacks := env.repo.outcomes(); commits != 0 || rollbacks != 1 {
+		t.Errorf("transactions = %d committed, %d rolled back, want the create rolled back", commits, rollbacks)
+	}
+	// subscribers never see a change that was rolled back
+	if got := drain(sub); len(got) != 0 {
+		t.Errorf("stream events = %v, want none", got)
+	}
+}
+
+func TestRevisionsFollowVersion(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	a := env.create(t, "Revisioned article")
+	if _, err := env.svc.Trash(ctx, a.ID, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.svc.Restore(ctx, a.ID, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	a, err := env.svc.Update(ctx, a.ID, UpdateArticleRequest{Title: "Revisioned article, second take"}, 0, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err = env.svc.RestoreRevision(ctx, a.ID, 1, "tester"); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := env.svc.ListRevisions(ctx, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]int, len(revs))
+	for i, rev := range revs {
+		got[i] = rev.Revision
+	}
+	// trash and restore bump the version without a snapshot
+	if fmt.Sprint(got) != "[1 4 5]" || int64(got[len(got)-1]) != a.Version {
+		t.Errorf("revisions = %v at version %d, want [1 4 5]", got, a.Version)
+	}
+}
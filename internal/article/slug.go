@@ -0,0 +1,75 @@
+package article
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+// slugRetries bounds how often a write is retried when a concurrent request
+// claimed the same slug between the availability check and the write.
+const slugRetries = 3
+
+// uniqueSlug derives a slug from title that no other article uses (now or
+// in its history), appending -2, -3, ... on collision.
+func (s *Service) uniqueSlug(ctx context.Context, title string, articleID int64) (string, error) {
+	base := slug.Make(title)
+	if base == "" {
+		base = "article"
+	}
+	candidate := base
+	for n := 2; ; n++ {
+		taken, err := s.repo.SlugTaken(ctx, candidate, articleID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// writeWithSlug assigns a fresh slug from a.Title and runs write with it
+func (s *Service) writeWithSlug(ctx context.Context, a Article, write func(Article) (Article, error)) (Article, error) {
+	return retrySlugConflict(func() (Article, error) {
+		sl, err := s.uniqueSlug(ctx, a.Title, a.ID)
+		if err != nil {
+			return Article{}, err
+		}
+		a.Slug = sl
+		return write(a)
+	})
+}
+
+// retrySlugConflict runs write again when a concurrent request claimed the
+// slug it picked. Every attempt has to start its own transaction: the
+// snapshot of the one that lost the race keeps showing the slug as free.
+func retrySlugConflict(write func() (Article, error)) (Article, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := write()
+		if err == ErrSlugConflict && attempt < slugRetries {
+			continue
+		}
+		return res, err
+	}
+}
+
+// GetBySlug looks up an article by its current slug. When slug is an old one,
+// the article is returned with moved = true so callers can redirect.
+func (s *Service) GetBySlug(ctx context.Context, sl string) (a Article, moved bool, err error) {
+	a, err = s.repo.FindBySlug(ctx, sl)
+	if err == nil {
+		return a, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return Article{}, false, err
+	}
+	a, err = s.repo.FindByOldSlug(ctx, sl)
+	if err != nil {
+		return Article{}, false, err
+	}
+	return a, true, nil
+}
@@ -0,0 +1,86 @@
+package article
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+)
+
+func TestNonLatinSlugs(t *testing.T) {
+	env := newTestEnv(t)
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleReader})
+	cases := map[string]string{
+		"Новости технологий сегодня":     "novosti-tekhnologiy-segodnya",
+		"Τεχνολογία και ειδήσεις σήμερα": "technologia-kai-eidiseis-simera",
+		"東京の最新テクノロジーニュースまとめ":             "東京の最新テクノロジーニュースまとめ",
+	}
+	for title, want := range cases {
+		a := env.create(t, title)
+		if a.Slug != want {
+			t.Errorf("slug of %q = %q, want %q", title, a.Slug, want)
+			continue
+		}
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/articles/slug/"+url.PathEscape(a.Slug), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != fiber.StatusOK {
+			t.Errorf("GET by slug %q = %d", a.Slug, res.StatusCode)
+		}
+	}
+
+	// the old slug redirects to the escaped new one
+	a := env.create(t, "東京のニュースまとめ記事です")
+	title := "大阪のニュースまとめ記事です"
+	if _, err := env.svc.Update(context.Background(), a.ID, UpdateArticleRequest{Title: title}, 0, "tester"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/articles/slug/"+url.PathEscape(a.Slug), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/articles/slug/" + url.PathEscape(title); res.StatusCode != fiber.StatusMovedPermanently || res.Header.Get(fiber.HeaderLocation) != want {
+		t.Errorf("old slug = %d %s, want a redirect to %s", res.StatusCode, res.Header.Get(fiber.HeaderLocation), want)
+	}
+}
+
+// staleSnapshotRepository sees no slug as taken until the transaction that
+// started the test ended, like a snapshot older than a concurrent insert
+type staleSnapshotRepository struct {
+	*txRepository
+	ended int
+}
+
+func (r staleSnapshotRepository) SlugTaken(ctx context.Context, slug string, exceptID int64) (bool, error) {
+	if commits, rollbacks := r.outcomes(); commits+rollbacks == r.ended {
+		return false, nil
+	}
+	return r.txRepository.SlugTaken(ctx, slug, exceptID)
+}
+
+func TestUpdateRetriesSlugConflictInNewTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	a := env.create(t, "Article losing the slug race")
+	taken := env.create(t, "Article winning the slug race")
+	commits, rollbacks := env.repo.outcomes()
+	repo := staleSnapshotRepository{txRepository: env.repo, ended: commits + rollbacks}
+	env.svc = NewService(repo, category.NewMemoryRepository(), env.audits, env.outbox, "test-secret")
+
+	got, err := env.svc.Update(ctx, a.ID, UpdateArticleRequest{Title: taken.Title}, 0, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := taken.Slug + "-2"; got.Slug != want {
+		t.Errorf("slug = %q, want %q", got.Slug, want)
+	}
+	if c, r := env.repo.outcomes(); c-commits != 1 || r-rollbacks != 1 {
+		t.Errorf("transactions = %d committed, %d rolled back, want the conflict retried in a new one", c-commits, r-rollbacks)
+	}
+}
@@ -0,0 +1,191 @@
+package article
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// sortableFields whitelists the columns accepted by ?sort=, in the order shown in errors
+var sortableFields = []string{"id", "title", "category", "status", "created_at", "updated_at"}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a "-updated_at,title" style spec. Unknown or repeated
+// fields are reported per item so they can go straight to response.Fail.
+func ParseSort(spec string) ([]SortField, []validatorpkg.FieldError) {
+	fields := make([]SortField, 0)
+	errs := make([]validatorpkg.FieldError, 0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		f := SortField{Field: strings.ToLower(part)}
+		if strings.HasPrefix(f.Field, "-") {
+			f.Desc = true
+			f.Field = f.Field[1:]
+		} else {
+			f.Field = strings.TrimPrefix(f.Field, "+")
+		}
+		switch {
+		case !isSortable(f.Field):
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "sort",
+				Message: fmt.Sprintf("sort field %q tidak valid, pilih salah satu dari: %s", f.Field, strings.Join(sortableFields, ", ")),
+				Tag:     "oneof",
+				Param:   f.Field,
+			})
+		case seen[f.Field]:
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "sort",
+				Message: fmt.Sprintf("sort field %q disebut lebih dari sekali", f.Field),
+				Tag:     "unique",
+				Param:   f.Field,
+			})
+		default:
+			seen[f.Field] = true
+			fields = append(fields, f)
+		}
+	}
+	return fields, errs
+}
+
+func isSortable(field string) bool {
+	for _, f := range sortableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSort makes the order total by ending with id, which keyset pagination relies on.
+func normalizeSort(fields []SortField) []SortField {
+	res := make([]SortField, 0, len(fields)+1)
+	for _, f := range fields {
+		res = append(res, f)
+		if f.Field == "id" {
+			// id is unique, later fields can never matter
+			return res
+		}
+	}
+	return append(res, SortField{Field: "id"})
+}
+
+// sortSpec is the canonical string form of fields, stored inside cursors
+func sortSpec(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortValue returns the typed value of a sortable field
+func sortValue(a Article, field string) interface{} {
+	switch field {
+	case "title":
+		return a.Title
+	case "category":
+		return a.Category
+	case "status":
+		return a.Status
+	case "created_at":
+		return a.CreatedAt
+	case "updated_at":
+		return a.UpdatedAt
+	default:
+		return a.ID
+	}
+}
+
+// cursorFor encodes the sort key of a so the next page can seek past it
+func cursorFor(a Article, fields []SortField, backward bool) Cursor {
+	cur := Cursor{ID: a.ID, Backward: backward, Sort: sortSpec(fields)}
+	for _, f := range fields[:len(fields)-1] {
+		switch v := sortValue(a, f.Field).(type) {
+		case time.Time:
+			cur.Values = append(cur.Values, v.UTC().Format(time.RFC3339Nano))
+		default:
+			cur.Values = append(cur.Values, fmt.Sprint(v))
+		}
+	}
+	return cur
+}
+
+// keyValues decodes the cursor into typed values matching fields, id last
+func keyValues(fields []SortField, cur Cursor) ([]interface{}, error) {
+	if len(cur.Values) != len(fields)-1 {
+		return nil, ErrInvalidCursor
+	}
+	res := make([]interface{}, 0, len(fields))
+	for i, f := range fields[:len(fields)-1] {
+		switch f.Field {
+		case "created_at", "updated_at":
+			t, err := time.Parse(time.RFC3339Nano, cur.Values[i])
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			res = append(res, t)
+		default:
+			res = append(res, cur.Values[i])
+		}
+	}
+	return append(res, cur.ID), nil
+}
+
+func compareValues(x, y interface{}) int {
+	switch xv := x.(type) {
+	case string:
+		return strings.Compare(xv, y.(string))
+	case time.Time:
+		return xv.Compare(y.(time.Time))
+	case int64:
+		yv := y.(int64)
+		switch {
+		case xv < yv:
+			return -1
+		case xv > yv:
+			return 1
+		}
+	}
+	return 0
+}
+
+// compareArticles orders a and b by fields (ascending/descending per field)
+func compareArticles(a, b Article, fields []SortField) int {
+	for _, f := range fields {
+		c := compareValues(sortValue(a, f.Field), sortValue(b, f.Field))
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareToKey orders a against a decoded cursor key using fields
+func compareToKey(a Article, fields []SortField, key []interface{}) int {
+	for i, f := range fields {
+		c := compareValues(sortValue(a, f.Field), key[i])
+		if f.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
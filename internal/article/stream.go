@@ -0,0 +1,222 @@
+package article
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+const (
+	// streamBufferSize bounds the events kept for Last-Event-ID resume
+	streamBufferSize = 1000
+	// subscriberBuffer is how far a client may fall behind before it is
+	// dropped; it reconnects and catches up from the ring buffer
+	subscriberBuffer = 64
+)
+
+// ChangeEvent is one committed article change sent to stream subscribers
+type ChangeEvent struct {
+	ID   uint64 `json:"id"`
+	Type string `json:"type"`
+	// Article is the article after the change, or before it for deletes
+	Article Article `json:"article"`
+	// before lets filters match articles that just left the filtered set
+	before *Article
+}
+
+// StreamFilter selects events by category slug and status; a change matches
+// when the article matches before or after it. Empty fields match everything.
+type StreamFilter struct {
+	Categories []string
+	Statuses   []string
+}
+
+func (f StreamFilter) match(e ChangeEvent) bool {
+	return f.matchArticle(e.Article) || (e.before != nil && f.matchArticle(*e.before))
+}
+
+func (f StreamFilter) matchArticle(a Article) bool {
+	if len(f.Categories) > 0 && !slices.Contains(f.Categories, slug.Make(a.Category)) {
+		return false
+	}
+	return len(f.Statuses) == 0 || slices.Contains(f.Statuses, a.Status)
+}
+
+// Subscription receives the events matching its filter on C. C is closed
+// when the subscriber falls too far behind or the broker is closed.
+type Subscription struct {
+	C      <-chan ChangeEvent
+	ch     chan ChangeEvent
+	filter StreamFilter
+}
+
+// Broker fans committed changes out to stream subscribers of this process
+// and keeps the latest ones in a ring buffer. Event ids start at the boot
+// time in microseconds, so ids handed out by an earlier process always look
+// older than the buffer.
+type Broker struct {
+	mu     sync.Mutex
+	lastID uint64
+	// ring holds up to streamBufferSize events, oldest at head once full
+	ring   []ChangeEvent
+	head   int
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		lastID: uint64(time.Now().UnixMicro()),
+		ring:   make([]ChangeEvent, 0, streamBufferSize),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish buffers the change and sends it to every matching subscriber
+func (b *Broker) Publish(typ string, before, after *Article) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e := ChangeEvent{ID: b.lastID, Type: typ, before: before}
+	if after != nil {
+		e.Article = *after
+	} else {
+		e.Article = *before
+		e.before = nil
+	}
+	if len(b.ring) < streamBufferSize {
+		b.ring = append(b.ring, e)
+	} else {
+		b.ring[b.head] = e
+		b.head = (b.head + 1) % streamBufferSize
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// too slow: drop it rather than block every writer
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. With a non-zero lastID it also returns
+// the buffered matching events after lastID; complete is false when events
+// after lastID were already evicted and the client has to reload its state.
+func (b *Broker) Subscribe(f StreamFilter, lastID uint64) (sub *Subscription, backlog []ChangeEvent, complete bool) {
+	ch := make(chan ChangeEvent, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: f}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return sub, nil, true
+	}
+	b.subs[sub] = struct{}{}
+	if lastID == 0 {
+		return sub, nil, true
+	}
+
+	backlog = make([]ChangeEvent, 0)
+	oldest := b.lastID + 1
+	for i := range b.ring {
+		e := b.ring[(b.head+i)%len(b.ring)]
+		if i == 0 {
+			oldest = e.ID
+		}
+		if e.ID > lastID && f.match(e) {
+			backlog = append(backlog, e)
+		}
+	}
+	complete = lastID+1 >= oldest && lastID <= b.lastID
+	return sub, backlog, complete
+}
+
+// Unsubscribe removes sub; safe to call more than once
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		b.drop(sub)
+	}
+}
+
+// Close ends every subscription, letting open streams finish on shutdown
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// drop closes and forgets sub. Caller must hold b.mu.
+func (b *Broker) drop(sub *Subscription) {
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+// pendingChanges collects the changes of a transaction until it commits
+type pendingChanges struct {
+	mu      sync.Mutex
+	changes [][2]*Article
+}
+
+type pendingKey struct{}
+
+// queueChange remembers a change for the stream of the transaction in ctx
+func queueChange(ctx context.Context, before, after *Article) {
+	if p, ok := ctx.Value(pendingKey{}).(*pendingChanges); ok {
+		p.mu.Lock()
+		p.changes = append(p.changes, [2]*Article{before, after})
+		p.mu.Unlock()
+	}
+}
+
+// changeType is the stream event of a change from before to after. Unlike the
+// webhooks the stream reports trash and restore as updates.
+func changeType(before, after *Article) string {
+	switch {
+	case before == nil:
+		return webhook.EventArticleCreated
+	case after == nil:
+		return webhook.EventArticleDeleted
+	}
+	return webhook.EventArticleUpdated
+}
+
+// withinTx runs fn in Repository.WithinTx and publishes the changes recorded
+// by fn once the outermost call commits, so subscribers never see a change
+// that was rolled back.
+func (s *Service) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(pendingKey{}).(*pendingChanges); ok {
+		return s.repo.WithinTx(ctx, fn)
+	}
+	p := &pendingChanges{}
+	if err := s.repo.WithinTx(context.WithValue(ctx, pendingKey{}, p), fn); err != nil {
+		return err
+	}
+	for _, c := range p.changes {
+		s.changes.Publish(changeType(c[0], c[1]), c[0], c[1])
+	}
+	return nil
+}
+
+// Changes is the broker streaming the committed changes of this service
+func (s *Service) Changes() *Broker {
+	return s.changes
+}
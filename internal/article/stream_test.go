@@ -0,0 +1,112 @@
+package article
+
+import (
+	"testing"
+)
+
+func publishN(b *Broker, n int, status string) []uint64 {
+	ids := make([]uint64, n)
+	for i := range ids {
+		a := Article{ID: int64(i + 1), Category: "Tech", Status: status}
+		b.Publish("article.updated", &a, &a)
+		ids[i] = b.lastID
+	}
+	return ids
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := NewBroker()
+	ids := publishN(b, 5, StatusPublish)
+
+	sub, backlog, complete := b.Subscribe(StreamFilter{}, ids[1])
+	defer b.Unsubscribe(sub)
+	if !complete || len(backlog) != 3 || backlog[0].ID != ids[2] || backlog[2].ID != ids[4] {
+		t.Fatalf("resume after %d = %d events, complete %v", ids[1], len(backlog), complete)
+	}
+	// live events follow the backlog
+	publishN(b, 1, StatusPublish)
+	if e := <-sub.C; e.ID != ids[4]+1 {
+		t.Errorf("live event id = %d, want %d", e.ID, ids[4]+1)
+	}
+
+	// up to date and new clients get no backlog
+	for _, last := range []uint64{0, b.lastID} {
+		sub, backlog, complete := b.Subscribe(StreamFilter{}, last)
+		b.Unsubscribe(sub)
+		if !complete || len(backlog) != 0 {
+			t.Errorf("resume after %d = %d events, complete %v", last, len(backlog), complete)
+		}
+	}
+}
+
+func TestBrokerResumeAfterEviction(t *testing.T) {
+	b := NewBroker()
+	ids := publishN(b, streamBufferSize+10, StatusDraft)
+	oldest := ids[10]
+
+	cases := []struct {
+		name     string
+		last     uint64
+		events   int
+		complete bool
+	}{
+		{"just before the buffer", oldest - 1, streamBufferSize, true},
+		{"inside the buffer", oldest + 9, streamBufferSize - 10, true},
+		{"evicted", ids[0], streamBufferSize, false},
+		// ids of an earlier process are lower than the boot time of this one
+		{"earlier process", 42, streamBufferSize, false},
+		{"future", b.lastID + 1, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, backlog, complete := b.Subscribe(StreamFilter{}, tc.last)
+			defer b.Unsubscribe(sub)
+			if len(backlog) != tc.events || complete != tc.complete {
+				t.Fatalf("got %d events, complete %v, want %d, %v", len(backlog), complete, tc.events, tc.complete)
+			}
+			for i := 1; i < len(backlog); i++ {
+				if backlog[i].ID != backlog[i-1].ID+1 {
+					t.Fatalf("backlog out of order at %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestBrokerFilter(t *testing.T) {
+	b := NewBroker()
+	start := b.lastID
+	draft := Article{ID: 1, Category: "Tech", Status: StatusDraft}
+	published := Article{ID: 1, Category: "Tech", Status: StatusPublish}
+	news := Article{ID: 2, Category: "News", Status: StatusPublish}
+	b.Publish("article.updated", &draft, &published)
+	b.Publish("article.updated", &news, &news)
+	// unpublished: still sent to publish subscribers, so they drop it
+	b.Publish("article.updated", &published, &draft)
+	b.Publish("article.deleted", &draft, nil)
+
+	sub, backlog, _ := b.Subscribe(StreamFilter{Categories: []string{"tech"}, Statuses: []string{StatusPublish}}, start)
+	defer b.Unsubscribe(sub)
+	if len(backlog) != 2 || backlog[0].Article.Status != StatusPublish || backlog[1].Article.Status != StatusDraft {
+		t.Fatalf("backlog = %+v", backlog)
+	}
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	sub, _, _ := b.Subscribe(StreamFilter{}, 0)
+	publishN(b, subscriberBuffer+1, StatusDraft)
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d events before the drop, want %d", n, subscriberBuffer)
+	}
+	// it catches up from the ring buffer when reconnecting
+	sub, backlog, complete := b.Subscribe(StreamFilter{}, b.lastID-1)
+	defer b.Unsubscribe(sub)
+	if !complete || len(backlog) != 1 {
+		t.Errorf("reconnect = %d events, complete %v", len(backlog), complete)
+	}
+}
@@ -0,0 +1,79 @@
+package article
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+type Tag struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	ArticleCount int64  `json:"article_count"`
+}
+
+// normalizeTags trims names and drops duplicates; "Go" and "go" are the same tag (same slug).
+func normalizeTags(names []string) []Tag {
+	seen := make(map[string]bool, len(names))
+	res := make([]Tag, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		s := slug.Make(name)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		res = append(res, Tag{Name: name, Slug: s})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// tagErrors rejects the tags normalizeTags would drop: a name without a slug,
+// like "++", and different names sharing a slug, like "C++" and "C#". The same
+// name in another case is only a duplicate.
+func tagErrors(names []string) []validatorpkg.FieldError {
+	var errs []validatorpkg.FieldError
+	seen := make(map[string]string, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		s := slug.Make(name)
+		prev, dup := seen[s]
+		switch {
+		case s == "":
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "tags",
+				Message: fmt.Sprintf("tag %q harus berisi huruf atau angka", name),
+				Tag:     "slug",
+				Param:   name,
+			})
+		case dup && !strings.EqualFold(prev, name):
+			errs = append(errs, validatorpkg.FieldError{
+				Field:   "tags",
+				Message: fmt.Sprintf("tag %q dan %q menghasilkan slug yang sama (%s)", prev, name, s),
+				Tag:     "unique",
+				Param:   name,
+			})
+		case !dup:
+			seen[s] = name
+		}
+	}
+	return errs
+}
+
+// tagsOrEmpty keeps the JSON field an array rather than null
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
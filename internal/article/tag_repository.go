@@ -0,0 +1,119 @@
+package article
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+func (r *MySQLRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	q := `
+    SELECT t.id, t.name, t.slug, COUNT(at.article_id) AS article_count
+    FROM tags t
+    LEFT JOIN article_tags at ON at.tag_id = t.id
+    GROUP BY t.id, t.name, t.slug
+    ORDER BY article_count DESC, t.name
+    `
+	rows, err := r.conn(ctx).QueryContext(ctx, q)
+	if err != nil {
+		return []Tag{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Tag, 0)
+	for rows.Next() {
+		var t Tag
+		if errScan := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.ArticleCount); errScan != nil {
+			return []Tag{}, errScan
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// loadTags fetches tag names of many articles in one query, avoiding N+1 lookups
+func (r *MySQLRepository) loadTags(ctx context.Context, ids []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	q := `
+    SELECT at.article_id, t.name
+    FROM article_tags at
+    JOIN tags t ON t.id = at.tag_id
+    WHERE at.article_id IN (` + placeholders(len(ids)) + `)
+    ORDER BY t.name
+    `
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if errScan := rows.Scan(&id, &name); errScan != nil {
+			return nil, errScan
+		}
+		res[id] = append(res[id], name)
+	}
+	return res, rows.Err()
+}
+
+// attachTags fills Tags of every article in items
+func (r *MySQLRepository) attachTags(ctx context.Context, items []Article) error {
+	ids := make([]int64, len(items))
+	for i, a := range items {
+		ids[i] = a.ID
+	}
+	tags, err := r.loadTags(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Tags = tagsOrEmpty(tags[items[i].ID])
+	}
+	return nil
+}
+
+// syncTags replaces the tags of an article, creating unknown tags on the fly
+func syncTags(ctx context.Context, tx database.Querier, articleID int64, names []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM article_tags WHERE article_id = ?`, articleID); err != nil {
+		return err
+	}
+	tags := normalizeTags(names)
+	if len(tags) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, 0, len(tags)*2)
+	for _, t := range tags {
+		// LAST_INSERT_ID(id) makes LastInsertId return the existing row on duplicates
+		res, err := tx.ExecContext(ctx, `
+        INSERT INTO tags (name, slug) VALUES (?, ?)
+        ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)
+        `, t.Name, t.Slug)
+		if err != nil {
+			return err
+		}
+		tagID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		ids = append(ids, articleID, tagID)
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?), ", len(tags)), ", ")
+	_, err := tx.ExecContext(ctx, `INSERT INTO article_tags (article_id, tag_id) VALUES `+values, ids...)
+	return err
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
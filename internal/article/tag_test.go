@@ -0,0 +1,63 @@
+package article
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+)
+
+func TestTagErrors(t *testing.T) {
+	cases := []struct {
+		tags    []string
+		invalid []string
+	}{
+		{[]string{"Go", "go", " GO "}, nil},
+		{[]string{"Новости", "東京", "web"}, nil},
+		{[]string{"C", "C++", "C#"}, []string{"C++", "C#"}},
+		{[]string{"++", "go"}, []string{"++"}},
+	}
+	for _, tc := range cases {
+		errs := tagErrors(tc.tags)
+		var got []string
+		for _, fe := range errs {
+			if fe.Field != "tags" || !strings.Contains(fe.Message, fe.Param) {
+				t.Errorf("%v: error %+v does not name the tag", tc.tags, fe)
+			}
+			got = append(got, fe.Param)
+		}
+		if strings.Join(got, " ") != strings.Join(tc.invalid, " ") {
+			t.Errorf("%v: rejected %v, want %v", tc.tags, got, tc.invalid)
+		}
+	}
+}
+
+func TestCreateRejectsCollidingTags(t *testing.T) {
+	env := newTestEnv(t)
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleEditor})
+	req := validRequest("Tagged article")
+	req.Tags = []string{"C#", "C++"}
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest(fiber.MethodPost, "/articles", bytes.NewReader(body))
+	r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	res, err := app.Test(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out response.Response
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", res.StatusCode)
+	}
+	if fe := out.Errors[0].(map[string]interface{}); fe["param"] != "C++" {
+		t.Errorf("error = %v, want it to name C++", fe)
+	}
+}
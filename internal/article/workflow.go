@@ -0,0 +1,128 @@
+package article
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Transition is one allowed status change of the editorial workflow
+type Transition struct {
+	Action string `json:"action"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// RequireComment makes a reviewer comment mandatory, e.g. when rejecting
+	RequireComment bool `json:"require_comment,omitempty"`
+}
+
+// Workflow is the editorial state machine. Every status change, whether
+// through an update, a transition endpoint, trash/restore or the scheduler,
+// must be listed here.
+var Workflow = []Transition{
+	{Action: "submit", From: StatusDraft, To: StatusReview},
+	{Action: "approve", From: StatusReview, To: StatusPublish},
+	{Action: "schedule", From: StatusReview, To: StatusScheduled},
+	{Action: "reject", From: StatusReview, To: StatusDraft, RequireComment: true},
+	{Action: "publish", From: StatusScheduled, To: StatusPublish},
+	{Action: "unschedule", From: StatusScheduled, To: StatusDraft},
+	{Action: "unpublish", From: StatusPublish, To: StatusDraft},
+	{Action: TransitionTrash, From: StatusDraft, To: StatusThrash},
+	{Action: TransitionTrash, From: StatusReview, To: StatusThrash},
+	{Action: TransitionTrash, From: StatusScheduled, To: StatusThrash},
+	{Action: TransitionTrash, From: StatusPublish, To: StatusThrash},
+	{Action: TransitionRestore, From: StatusThrash, To: StatusDraft},
+}
+
+// EntryStatuses are the statuses a new article may be created in. Review and
+// thrash are only reached through the Workflow.
+var EntryStatuses = []string{StatusDraft, StatusPublish, StatusScheduled}
+
+func isEntryStatus(status string) bool {
+	return slices.Contains(EntryStatuses, status)
+}
+
+// trash and restore also keep the trash bookkeeping (previous_status,
+// trashed_at) so they are carried out by Repository.Trash and Restore
+const (
+	TransitionTrash   = "trash"
+	TransitionRestore = "restore"
+)
+
+// NextTransitions lists the transitions available from status
+func NextTransitions(status string) []Transition {
+	res := make([]Transition, 0)
+	for _, t := range Workflow {
+		if t.From == status {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// FindTransition looks up action for an article in status from
+func FindTransition(from, action string) (Transition, bool) {
+	for _, t := range Workflow {
+		if t.From == from && t.Action == action {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// transitionTo looks up the transition moving from into to
+func transitionTo(from, to string) (Transition, bool) {
+	for _, t := range Workflow {
+		if t.From == from && t.To == to {
+			return t, true
+		}
+	}
+	return Transition{}, false
+}
+
+// TransitionError rejects a status change the workflow does not allow. Either
+// Action or To is set, depending on how the change was requested.
+type TransitionError struct {
+	From    string
+	Action  string
+	To      string
+	Allowed []Transition
+}
+
+func (e *TransitionError) Error() string {
+	msg := fmt.Sprintf("status %s tidak bisa berubah ke %s", e.From, e.To)
+	if e.Action != "" {
+		msg = fmt.Sprintf("aksi %s tidak diizinkan untuk status %s", e.Action, e.From)
+	}
+	if len(e.Allowed) == 0 {
+		return msg
+	}
+	next := make([]string, len(e.Allowed))
+	for i, t := range e.Allowed {
+		next[i] = fmt.Sprintf("%s (%s)", t.To, t.Action)
+	}
+	return msg + ", status berikutnya yang diizinkan: " + strings.Join(next, ", ")
+}
+
+// updatable drops the transitions that only the trash and restore
+// operations may perform
+func updatable(ts []Transition) []Transition {
+	res := make([]Transition, 0, len(ts))
+	for _, t := range ts {
+		if t.From != StatusThrash && t.To != StatusThrash {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// transitionAction is the policy action a transition needs besides the one
+// implied by its target status
+func transitionAction(t Transition) Action {
+	switch {
+	case t.To == StatusThrash:
+		return ActionTrash
+	case t.From == StatusThrash:
+		return ActionRestore
+	}
+	return ActionUpdate
+}
@@ -0,0 +1,169 @@
+package article
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+)
+
+func TestWorkflowTable(t *testing.T) {
+	statuses := map[string]bool{StatusDraft: true, StatusReview: true, StatusScheduled: true, StatusPublish: true, StatusThrash: true}
+	seen := make(map[[2]string]bool)
+	for _, tr := range Workflow {
+		if !statuses[tr.From] || !statuses[tr.To] || tr.From == tr.To {
+			t.Errorf("%s: invalid change %s -> %s", tr.Action, tr.From, tr.To)
+		}
+		key := [2]string{tr.From, tr.Action}
+		if seen[key] {
+			t.Errorf("action %s listed twice for %s", tr.Action, tr.From)
+		}
+		seen[key] = true
+	}
+	// everything but the trash itself can be trashed, and only restored to draft
+	for status := range statuses {
+		tr, ok := FindTransition(status, TransitionTrash)
+		if ok != (status != StatusThrash) || (ok && tr.To != StatusThrash) {
+			t.Errorf("trash from %s = %+v, %v", status, tr, ok)
+		}
+	}
+	if next := NextTransitions(StatusThrash); len(next) != 1 || next[0].Action != TransitionRestore || next[0].To != StatusDraft {
+		t.Errorf("transitions from thrash = %+v", next)
+	}
+}
+
+// reach creates an article and walks it to status through the workflow
+func reach(t *testing.T, env *testEnv, status string) Article {
+	t.Helper()
+	ctx := context.Background()
+	a := env.create(t, "Workflow "+status)
+	steps := map[string][]string{
+		StatusReview:    {"submit"},
+		StatusPublish:   {"submit", "approve"},
+		StatusScheduled: {"submit", "schedule"},
+		StatusThrash:    {TransitionTrash},
+	}[status]
+	for _, action := range steps {
+		req := TransitionRequest{}
+		if action == "schedule" {
+			at := time.Now().Add(time.Hour)
+			req.PublishAt = &at
+		}
+		var err error
+		if a, err = env.svc.Transition(ctx, a.ID, action, req, "tester"); err != nil {
+			t.Fatalf("%s to reach %s: %v", action, status, err)
+		}
+	}
+	if a.Status != status {
+		t.Fatalf("reached %s, want %s", a.Status, status)
+	}
+	return a
+}
+
+func TestEveryTransition(t *testing.T) {
+	for _, tr := range Workflow {
+		t.Run(tr.From+"/"+tr.Action, func(t *testing.T) {
+			env := newTestEnv(t)
+			ctx := context.Background()
+			a := reach(t, env, tr.From)
+
+			req := TransitionRequest{}
+			if tr.To == StatusScheduled {
+				at := time.Now().Add(time.Hour)
+				req.PublishAt = &at
+			}
+			if tr.RequireComment {
+				if _, err := env.svc.Transition(ctx, a.ID, tr.Action, req, "tester"); !errors.Is(err, ErrCommentRequired) {
+					t.Fatalf("without comment = %v, want ErrCommentRequired", err)
+				}
+				req.Comment = "needs work"
+			}
+			got, err := env.svc.Transition(ctx, a.ID, tr.Action, req, "tester")
+			if err != nil {
+				t.Fatalf("Transition: %v", err)
+			}
+			if got.Status != tr.To {
+				t.Fatalf("status = %s, want %s", got.Status, tr.To)
+			}
+			history, err := env.svc.ListTransitions(ctx, a.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := history[len(history)-1]
+			if last.Action != tr.Action || last.From != tr.From || last.To != tr.To || last.Comment != req.Comment {
+				t.Errorf("recorded %+v", last)
+			}
+			// the same action is not available from the new status
+			if _, ok := FindTransition(tr.To, tr.Action); !ok {
+				var te *TransitionError
+				if _, err := env.svc.Transition(ctx, a.ID, tr.Action, req, "tester"); !errors.As(err, &te) {
+					t.Errorf("repeating %s = %v, want a TransitionError", tr.Action, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateStatusFollowsWorkflow(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	a := reach(t, env, StatusDraft)
+
+	var te *TransitionError
+	// skipping review is not allowed, neither is trashing through an update
+	for _, status := range []string{StatusPublish, StatusThrash} {
+		if _, err := env.svc.Update(ctx, a.ID, UpdateArticleRequest{Status: status}, 0, "tester"); !errors.As(err, &te) {
+			t.Errorf("update to %s = %v, want a TransitionError", status, err)
+		}
+	}
+	got, err := env.svc.Update(ctx, a.ID, UpdateArticleRequest{Status: StatusReview}, 0, "tester")
+	if err != nil || got.Status != StatusReview {
+		t.Fatalf("update to review = %+v, %v", got, err)
+	}
+	if _, err := env.svc.Update(ctx, a.ID, UpdateArticleRequest{Status: StatusDraft}, 0, "tester"); !errors.Is(err, ErrCommentRequired) {
+		t.Errorf("reject without comment = %v, want ErrCommentRequired", err)
+	}
+}
+
+func TestCreateOnlyInEntryStatuses(t *testing.T) {
+	env := newTestEnv(t)
+	app := newTestApp(env, auth.Principal{UserID: 1, Role: auth.RoleAdmin})
+	for _, status := range []string{StatusReview, StatusThrash} {
+		req := validRequest("Created as " + status)
+		req.Status = status
+		if _, err := env.svc.Create(context.Background(), req, 0); !errors.Is(err, ErrInvalidEntryStatus) {
+			t.Errorf("Create as %s = %v, want ErrInvalidEntryStatus", status, err)
+		}
+		body, _ := json.Marshal(req)
+		r := httptest.NewRequest(fiber.MethodPost, "/articles", bytes.NewReader(body))
+		r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		res, err := app.Test(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != fiber.StatusUnprocessableEntity {
+			t.Errorf("POST as %s = %d, want 422", status, res.StatusCode)
+		}
+	}
+	if commits, rollbacks := env.repo.outcomes(); commits != 0 || rollbacks != 0 {
+		t.Errorf("transactions = %d committed, %d rolled back, want none", commits, rollbacks)
+	}
+	// bulk creates are validated the same way
+	req := validRequest("Bulk created as thrash")
+	req.Status = StatusThrash
+	create, _ := json.Marshal(req)
+	status, out := postBulk(t, app, BulkRequest{Atomic: true, Operations: []BulkOperation{{Op: BulkCreate, Data: create}}})
+	if status != fiber.StatusUnprocessableEntity || len(out.Errors) != 1 {
+		t.Fatalf("bulk create as thrash = %d %+v, want 422", status, out.Errors)
+	}
+	if fe := out.Errors[0].(map[string]interface{}); fe["field"] != "operations[0].data.status" {
+		t.Errorf("error field = %v", fe["field"])
+	}
+}
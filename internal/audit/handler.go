@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// Register mounts the audit endpoints, e.g. on /audit. Every route runs
+// requireUser and then requireAdmin.
+func (h *Handler) Register(r fiber.Router, requireUser, requireAdmin fiber.Handler) {
+	r.Get("/", requireUser, requireAdmin, h.list)
+}
+
+// list filters by actor, article_id, action and a from/to time range
+func (h *Handler) list(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+
+	errs := make([]validatorpkg.FieldError, 0)
+	f := Filter{
+		Actor:  strings.TrimSpace(c.Query("actor")),
+		Action: strings.TrimSpace(c.Query("action")),
+	}
+	if v := c.Query("article_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			errs = append(errs, validatorpkg.FieldError{Field: "article_id", Message: "article_id harus integer", Tag: "numeric", Param: v})
+		}
+		f.ArticleID = id
+	}
+	var err *validatorpkg.FieldError
+	if f.From, err = parseTimeQuery(c, "from", false); err != nil {
+		errs = append(errs, *err)
+	}
+	if f.To, err = parseTimeQuery(c, "to", true); err != nil {
+		errs = append(errs, *err)
+	}
+	if len(errs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, errs)
+	}
+
+	items, meta, errList := h.svc.List(c.Context(), f, page, limit)
+	if errList != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, errList.Error())
+	}
+
+	return response.Success(c, fiber.StatusOK, map[string]interface{}{
+		"items": items,
+		"meta":  meta,
+	}, "audit events retrieved successfully")
+}
+
+// parseTimeQuery accepts RFC3339 or a plain date. A plain date used as an
+// upper bound covers the whole day.
+func parseTimeQuery(c *fiber.Ctx, key string, endOfDay bool) (*time.Time, *validatorpkg.FieldError) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+	if err != nil {
+		return nil, &validatorpkg.FieldError{
+			Field:   key,
+			Message: fmt.Sprintf("%s harus berformat RFC3339 atau YYYY-MM-DD", key),
+			Tag:     "datetime",
+			Param:   v,
+		}
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return &t, nil
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+// MemoryRepository menyimpan audit event di memory untuk STORAGE_DRIVER=memory.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	events []Event
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, e Event) (Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	e.ID = r.nextID
+	e.CreatedAt = time.Now().Truncate(time.Second)
+	r.events = append(r.events, e)
+	database.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = slices.DeleteFunc(r.events, func(x Event) bool { return x.ID == e.ID })
+	})
+	return e, nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context, f Filter, limit, offset int) ([]Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]Event, 0)
+	skipped := 0
+	for i := len(r.events) - 1; i >= 0 && len(res) < limit; i-- {
+		e := r.events[i]
+		if !matchFilter(e, f) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+func matchFilter(e Event, f Filter) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.ArticleID != 0 && e.ArticleID != f.ArticleID:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.From != nil && e.CreatedAt.Before(*f.From):
+		return false
+	case f.To != nil && e.CreatedAt.After(*f.To):
+		return false
+	}
+	return true
+}
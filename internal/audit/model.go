@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event records one mutation of an article: who did what, and the article
+// before and after the change.
+type Event struct {
+	ID        int64  `json:"id"`
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	ArticleID int64  `json:"article_id"`
+	// Before is empty for creates, After for deletes
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	IP        string          `json:"ip,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Filter selects events; zero fields match everything
+type Filter struct {
+	Actor     string
+	ArticleID int64
+	Action    string
+	From      *time.Time
+	To        *time.Time
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+type Repository interface {
+	// Insert stores e. With MySQL it joins the transaction carried by ctx, so
+	// the event is committed or rolled back together with the mutation.
+	Insert(ctx context.Context, e Event) (Event, error)
+	// List returns matching events, newest first
+	List(ctx context.Context, f Filter, limit, offset int) ([]Event, error)
+}
+
+const eventColumns = `id, actor, action, article_id, before_data, after_data, request_id, ip, created_at`
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) *MySQLRepository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) Insert(ctx context.Context, e Event) (Event, error) {
+	q := `
+    INSERT INTO audit_events (actor, action, article_id, before_data, after_data, request_id, ip)
+    VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+	res, err := database.Conn(ctx, r.db).ExecContext(ctx, q, e.Actor, e.Action, e.ArticleID, nullJSON(e.Before), nullJSON(e.After), e.RequestID, e.IP)
+	if err != nil {
+		return Event{}, err
+	}
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func (r *MySQLRepository) List(ctx context.Context, f Filter, limit, offset int) ([]Event, error) {
+	conds := make([]string, 0)
+	args := make([]interface{}, 0)
+	if f.Actor != "" {
+		conds = append(conds, "actor = ?")
+		args = append(args, f.Actor)
+	}
+	if f.ArticleID != 0 {
+		conds = append(conds, "article_id = ?")
+		args = append(args, f.ArticleID)
+	}
+	if f.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, f.Action)
+	}
+	if f.From != nil {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, *f.From)
+	}
+	if f.To != nil {
+		conds = append(conds, "created_at <= ?")
+		args = append(args, *f.To)
+	}
+
+	q := `SELECT ` + eventColumns + ` FROM audit_events`
+	if len(conds) > 0 {
+		q += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	q += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return []Event{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Event, 0)
+	for rows.Next() {
+		var e Event
+		var before, after []byte
+		if errScan := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.ArticleID, &before, &after, &e.RequestID, &e.IP, &e.CreatedAt); errScan != nil {
+			return []Event{}, errScan
+		}
+		e.Before, e.After = before, after
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+// nullJSON stores an empty document as NULL
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
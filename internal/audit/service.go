@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+)
+
+// maxLimit caps the page size of List
+const maxLimit = 100
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns one page of events matching f, newest first
+func (s *Service) List(ctx context.Context, f Filter, page, limit int) ([]Event, response.Meta, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	limit = min(limit, maxLimit)
+	page = max(page, 1)
+
+	// fetch one extra row to know whether another page exists
+	items, err := s.repo.List(ctx, f, limit+1, (page-1)*limit)
+	if err != nil {
+		return []Event{}, response.Meta{}, err
+	}
+	hasNext := len(items) > limit
+	if hasNext {
+		items = items[:limit]
+	}
+	return items, response.Meta{Limit: limit, Page: page, HasNext: hasNext}, nil
+}
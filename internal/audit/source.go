@@ -0,0 +1,29 @@
+package audit
+
+import "context"
+
+// Source describes where a mutation comes from. Handlers put it in the
+// context so services can record it without knowing about HTTP.
+type Source struct {
+	Actor     string
+	RequestID string
+	IP        string
+}
+
+// SystemActor is recorded when a mutation carries no Source
+const SystemActor = "system"
+
+type sourceKey struct{}
+
+func WithSource(ctx context.Context, s Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, s)
+}
+
+// SourceFrom returns the Source stored by WithSource, attributed to
+// SystemActor when there is none
+func SourceFrom(ctx context.Context) Source {
+	if s, ok := ctx.Value(sourceKey{}).(Source); ok {
+		return s
+	}
+	return Source{Actor: SystemActor}
+}
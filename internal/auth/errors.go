@@ -0,0 +1,10 @@
+package auth
+
+import "errors"
+
+var (
+	ErrEmailTaken         = errors.New("email sudah terdaftar")
+	ErrInvalidCredentials = errors.New("email atau password salah")
+	ErrInvalidToken       = errors.New("token tidak valid atau sudah kedaluwarsa")
+	ErrForbidden          = errors.New("akses ditolak untuk role ini")
+)
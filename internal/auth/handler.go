@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+type Handler struct {
+	svc       *Service
+	validator *validatorpkg.Validator
+}
+
+func NewHandler(svc *Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{svc: svc, validator: validator}
+}
+
+func (h *Handler) Register(r fiber.Router) {
+	r.Post("/register", h.register)
+	r.Post("/login", h.login)
+	r.Post("/refresh", h.refresh)
+	r.Get("/me", RequireUser(h.svc), h.me)
+}
+
+// RegisterUsers mounts the user management endpoints, e.g. on /users. Admin only.
+func (h *Handler) RegisterUsers(r fiber.Router) {
+	r.Put("/:id/role", RequireUser(h.svc), RequireRole(RoleAdmin), h.updateRole)
+}
+
+func (h *Handler) register(c *fiber.Ctx) error {
+	var req RegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	u, err := h.svc.Register(c.Context(), req)
+	if err != nil {
+		if err == ErrEmailTaken {
+			return response.Fail(c, fiber.StatusConflict, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusCreated, u, "user registered successfully")
+}
+
+func (h *Handler) login(c *fiber.Ctx) error {
+	var req LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	tokens, err := h.svc.Login(c.Context(), req)
+	if err != nil {
+		if err == ErrInvalidCredentials {
+			return response.Fail(c, fiber.StatusUnauthorized, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, tokens, "login successful")
+}
+
+func (h *Handler) refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	tokens, err := h.svc.Refresh(c.Context(), req.RefreshToken)
+	if err != nil {
+		if err == ErrInvalidToken {
+			return response.Fail(c, fiber.StatusUnauthorized, err.Error())
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, tokens, "token refreshed successfully")
+}
+
+func (h *Handler) updateRole(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	if p, _ := PrincipalFrom(c); p.APIKeyID != 0 {
+		return response.Fail(c, fiber.StatusForbidden, "API key tidak boleh mengelola user")
+	}
+	var req UpdateRoleRequest
+	if errBody := c.BodyParser(&req); errBody != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	u, err := h.svc.SetRole(c.Context(), id, req.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "user tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, u, "user role updated successfully")
+}
+
+func (h *Handler) me(c *fiber.Ctx) error {
+	p, _ := PrincipalFrom(c)
+	u, err := h.svc.GetUser(c.Context(), p.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "user tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, u, "user retrieved successfully")
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// MemoryRepository menyimpan user di memory untuk STORAGE_DRIVER=memory.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	items  map[int64]User
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{items: make(map[int64]User)}
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, in User) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, u := range r.items {
+		if u.Email == in.Email {
+			return User{}, ErrEmailTaken
+		}
+	}
+	r.nextID++
+	now := time.Now().Truncate(time.Second)
+	u := User{ID: r.nextID, Name: in.Name, Email: in.Email, PasswordHash: in.PasswordHash, Role: in.Role, CreatedAt: now, UpdatedAt: now}
+	r.items[u.ID] = u
+	return u, nil
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.items[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r *MemoryRepository) UpdateRole(ctx context.Context, id int64, role Role) (User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.items[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	u.Role = role
+	u.UpdatedAt = time.Now().Truncate(time.Second)
+	r.items[id] = u
+	return u, nil
+}
+
+func (r *MemoryRepository) FindByEmail(ctx context.Context, email string) (User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.items {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+)
+
+const principalKey = "auth.principal"
+
+// RequireUser rejects requests without a valid "Authorization: Bearer <access token>"
+// and stores the caller for PrincipalFrom. Requests already authenticated by
+// an earlier middleware (API key) pass through.
+func RequireUser(svc *Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := PrincipalFrom(c); ok {
+			return c.Next()
+		}
+		token, ok := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			return response.Fail(c, fiber.StatusUnauthorized, "header Authorization Bearer wajib diisi")
+		}
+		p, err := svc.Authenticate(token)
+		if err != nil {
+			return response.Fail(c, fiber.StatusUnauthorized, err.Error())
+		}
+		SetPrincipal(c, p)
+		return c.Next()
+	}
+}
+
+// SetPrincipal records the authenticated caller of the request
+func SetPrincipal(c *fiber.Ctx, p Principal) {
+	c.Locals(principalKey, p)
+}
+
+// RequireRole only lets through callers authenticated by RequireUser whose role is in roles
+func RequireRole(roles ...Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := PrincipalFrom(c)
+		if !ok {
+			return response.Fail(c, fiber.StatusUnauthorized, "header Authorization Bearer wajib diisi")
+		}
+		for _, r := range roles {
+			if p.Role == r {
+				return c.Next()
+			}
+		}
+		return response.Fail(c, fiber.StatusForbidden, ErrForbidden.Error())
+	}
+}
+
+// PrincipalFrom returns the caller authenticated by RequireUser
+func PrincipalFrom(c *fiber.Ctx) (Principal, bool) {
+	p, ok := c.Locals(principalKey).(Principal)
+	return p, ok
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
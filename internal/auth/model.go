@@ -0,0 +1,44 @@
+package auth
+
+import "time"
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleWriter Role = "writer"
+	RoleReader Role = "reader"
+)
+
+// Roles lists every valid role
+var Roles = []Role{RoleAdmin, RoleEditor, RoleWriter, RoleReader}
+
+func IsValidRole(role Role) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	Role         Role      `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Principal is the authenticated caller of a request: a user, or a machine
+// client authenticated by API key (UserID 0, APIKeyID set).
+type Principal struct {
+	UserID   int64  `json:"user_id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     Role   `json:"role"`
+	APIKeyID int64  `json:"api_key_id,omitempty"`
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type Repository interface {
+	// Insert stores a new user. An email already in use returns ErrEmailTaken.
+	Insert(ctx context.Context, u User) (User, error)
+	FindByID(ctx context.Context, id int64) (User, error)
+	FindByEmail(ctx context.Context, email string) (User, error)
+	UpdateRole(ctx context.Context, id int64, role Role) (User, error)
+}
+
+const userColumns = `id, name, email, password_hash, role, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(s rowScanner) (User, error) {
+	var u User
+	err := s.Scan(&u.ID, &u.Name, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	return u, err
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) *MySQLRepository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) Insert(ctx context.Context, u User) (User, error) {
+	q := `
+    INSERT INTO users (name, email, password_hash, role)
+    VALUES (?, ?, ?, ?)
+    `
+	res, err := r.db.ExecContext(ctx, q, u.Name, u.Email, u.PasswordHash, u.Role)
+	if err != nil {
+		var myErr *mysql.MySQLError
+		if errors.As(err, &myErr) && myErr.Number == 1062 {
+			return User{}, ErrEmailTaken
+		}
+		return User{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return User{}, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MySQLRepository) FindByID(ctx context.Context, id int64) (User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	return scanUser(r.db.QueryRowContext(ctx, q, id))
+}
+
+func (r *MySQLRepository) UpdateRole(ctx context.Context, id int64, role Role) (User, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET role = ? WHERE id = ?`, role, id)
+	if err != nil {
+		return User{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// unchanged rows are not counted, tell them apart from missing ones
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return User{}, err
+		}
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MySQLRepository) FindByEmail(ctx context.Context, email string) (User, error) {
+	q := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	return scanUser(r.db.QueryRowContext(ctx, q, email))
+}
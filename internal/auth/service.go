@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash is compared against when the email is unknown so login takes the
+// same time whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type Service struct {
+	repo       Repository
+	tokens     tokenIssuer
+	adminEmail string
+}
+
+// NewService creates the auth service. secret signs the JWTs; when empty a
+// random per-process secret is used. The account of adminEmail, when set,
+// is registered as admin (see EnsureAdmin).
+func NewService(repo Repository, secret string, accessTTL, refreshTTL time.Duration, adminEmail string) *Service {
+	return &Service{repo: repo, tokens: newTokenIssuer(secret, accessTTL, refreshTTL), adminEmail: normalizeEmail(adminEmail)}
+}
+
+func (s *Service) Register(ctx context.Context, req RegisterRequest) (User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return User{}, err
+	}
+	// new accounts can only read until an admin grants them a role
+	u := User{
+		Name:         strings.TrimSpace(req.Name),
+		Email:        normalizeEmail(req.Email),
+		PasswordHash: string(hash),
+		Role:         RoleReader,
+	}
+	if s.adminEmail != "" && u.Email == s.adminEmail {
+		u.Role = RoleAdmin
+	}
+	return s.repo.Insert(ctx, u)
+}
+
+// EnsureAdmin promotes the account of the configured admin email when it
+// already exists, so an operator can recover admin access. It reports
+// whether a user was promoted.
+func (s *Service) EnsureAdmin(ctx context.Context) (bool, error) {
+	if s.adminEmail == "" {
+		return false, nil
+	}
+	u, err := s.repo.FindByEmail(ctx, s.adminEmail)
+	if err == sql.ErrNoRows || (err == nil && u.Role == RoleAdmin) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = s.repo.UpdateRole(ctx, u.ID, RoleAdmin)
+	return err == nil, err
+}
+
+// Login checks the credentials and issues a new token pair
+func (s *Service) Login(ctx context.Context, req LoginRequest) (TokenPair, error) {
+	u, err := s.repo.FindByEmail(ctx, normalizeEmail(req.Email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(req.Password))
+			return TokenPair{}, ErrInvalidCredentials
+		}
+		return TokenPair{}, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)) != nil {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	return s.tokens.issue(u)
+}
+
+// Refresh trades a valid refresh token for a new token pair
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	_, id, err := s.tokens.parse(refreshToken, tokenTypeRefresh)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	// the user may have been removed since the token was issued
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return TokenPair{}, ErrInvalidToken
+		}
+		return TokenPair{}, err
+	}
+	return s.tokens.issue(u)
+}
+
+// Authenticate resolves an access token into the principal it was issued to
+func (s *Service) Authenticate(accessToken string) (Principal, error) {
+	claims, id, err := s.tokens.parse(accessToken, tokenTypeAccess)
+	if err != nil {
+		return Principal{}, err
+	}
+	return Principal{UserID: id, Name: claims.Name, Email: claims.Email, Role: claims.Role}, nil
+}
+
+func (s *Service) GetUser(ctx context.Context, id int64) (User, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// SetRole changes the role of a user. It applies to tokens issued afterwards,
+// i.e. after the next login or refresh.
+func (s *Service) SetRole(ctx context.Context, id int64, role Role) (User, error) {
+	return s.repo.UpdateRole(ctx, id, role)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"strconv"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/jwt"
+)
+
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	// Type keeps refresh tokens from being used as access tokens and vice versa
+	Type  string `json:"typ"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Role  Role   `json:"role,omitempty"`
+}
+
+type tokenIssuer struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// newTokenIssuer falls back to a random secret, which invalidates every token
+// on restart and across replicas.
+func newTokenIssuer(secret string, accessTTL, refreshTTL time.Duration) tokenIssuer {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return tokenIssuer{secret: key, accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
+func (t tokenIssuer) issue(u User) (TokenPair, error) {
+	now := time.Now()
+	access, err := t.sign(u, tokenTypeAccess, now, t.accessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := t.sign(u, tokenTypeRefresh, now, t.refreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(t.accessTTL.Seconds()),
+	}, nil
+}
+
+func (t tokenIssuer) sign(u User, typ string, now time.Time, ttl time.Duration) (string, error) {
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(u.ID, 10),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+		Type: typ,
+	}
+	if typ == tokenTypeAccess {
+		claims.Name, claims.Email, claims.Role = u.Name, u.Email, u.Role
+	}
+	return jwt.Sign(claims, t.secret)
+}
+
+// parse verifies token and checks it is of the expected type
+func (t tokenIssuer) parse(token, typ string) (tokenClaims, int64, error) {
+	var claims tokenClaims
+	if err := jwt.Parse(token, t.secret, &claims); err != nil || claims.Type != typ {
+		return tokenClaims{}, 0, ErrInvalidToken
+	}
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return tokenClaims{}, 0, ErrInvalidToken
+	}
+	return claims, id, nil
+}
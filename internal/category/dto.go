@@ -0,0 +1,12 @@
+package category
+
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=100"`
+	ParentID *int64 `json:"parent_id" validate:"omitempty,min=0"`
+}
+
+type UpdateCategoryRequest struct {
+	Name string `json:"name" validate:"omitempty,min=3,max=100"`
+	// ParentID nil keeps the current parent, 0 moves the category to the root
+	ParentID *int64 `json:"parent_id" validate:"omitempty,min=0"`
+}
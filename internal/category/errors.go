@@ -0,0 +1,13 @@
+package category
+
+import "errors"
+
+var (
+	ErrNameTaken      = errors.New("category dengan nama tersebut sudah ada")
+	ErrInvalidName    = errors.New("name harus mengandung huruf atau angka")
+	ErrParentNotFound = errors.New("parent category tidak ditemukan")
+	// ErrCycle means the requested parent is the category itself or one of its descendants
+	ErrCycle       = errors.New("parent category tidak boleh category itu sendiri atau turunannya")
+	ErrHasChildren = errors.New("category masih memiliki sub category")
+	ErrInUse       = errors.New("category masih dipakai oleh article")
+)
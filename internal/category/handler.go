@@ -0,0 +1,113 @@
+package category
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+type Handler struct {
+	svc       *Service
+	validator *validatorpkg.Validator
+}
+
+func NewHandler(svc *Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{svc: svc, validator: validator}
+}
+
+// Register mounts the category endpoints. Mutating routes run requireUser and
+// then requireEditor.
+func (h *Handler) Register(r fiber.Router, requireUser, requireEditor fiber.Handler) {
+	r.Post("/", requireUser, requireEditor, h.create)
+	r.Get("/", h.list)
+	r.Get("/:id", h.getByID)
+	r.Put("/:id", requireUser, requireEditor, h.update)
+	r.Delete("/:id", requireUser, requireEditor, h.delete)
+}
+
+func (h *Handler) create(c *fiber.Ctx) error {
+	var req CreateCategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	cat, err := h.svc.Create(c.Context(), req)
+	if err != nil {
+		return failWrite(c, err)
+	}
+	return response.Success(c, fiber.StatusCreated, cat, "category created successfully")
+}
+
+func (h *Handler) list(c *fiber.Ctx) error {
+	items, err := h.svc.List(c.Context(), c.QueryBool("tree"))
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, items, "categories retrieved successfully")
+}
+
+func (h *Handler) getByID(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	cat, err := h.svc.GetByID(c.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "category tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, cat, "category retrieved successfully")
+}
+
+func (h *Handler) update(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	var req UpdateCategoryRequest
+	if errBody := c.BodyParser(&req); errBody != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	cat, err := h.svc.Update(c.Context(), id, req)
+	if err != nil {
+		return failWrite(c, err)
+	}
+	return response.Success(c, fiber.StatusOK, cat, "category updated successfully")
+}
+
+func (h *Handler) delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	if err := h.svc.Delete(c.Context(), id); err != nil {
+		return failWrite(c, err)
+	}
+	return response.Success(c, fiber.StatusOK, nil, "category deleted successfully")
+}
+
+// failWrite maps the errors of create, update and delete to a response
+func failWrite(c *fiber.Ctx, err error) error {
+	switch err {
+	case sql.ErrNoRows:
+		return response.Fail(c, fiber.StatusNotFound, "category tidak ditemukan")
+	case ErrNameTaken, ErrHasChildren, ErrInUse:
+		return response.Fail(c, fiber.StatusConflict, err.Error())
+	case ErrInvalidName, ErrParentNotFound, ErrCycle:
+		return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+	return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+}
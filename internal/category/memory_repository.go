@@ -0,0 +1,138 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository menyimpan category di memory, pasangan dari
+// article.MemoryRepository untuk STORAGE_DRIVER=memory.
+type MemoryRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	items  map[int64]Category
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{items: make(map[int64]Category)}
+}
+
+func (r *MemoryRepository) Insert(ctx context.Context, in Category) (Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.slugTaken(in.Slug, 0) {
+		return Category{}, ErrNameTaken
+	}
+	if in.ParentID != nil {
+		if _, ok := r.items[*in.ParentID]; !ok {
+			return Category{}, ErrParentNotFound
+		}
+	}
+	r.nextID++
+	now := time.Now().Truncate(time.Second)
+	c := Category{ID: r.nextID, ParentID: in.ParentID, Name: in.Name, Slug: in.Slug, CreatedAt: now, UpdatedAt: now}
+	r.items[c.ID] = c
+	return c, nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context) ([]Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]Category, 0, len(r.items))
+	for _, c := range r.items {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *MemoryRepository) FindByID(ctx context.Context, id int64) (Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.items[id]
+	if !ok {
+		return Category{}, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (r *MemoryRepository) FindBySlug(ctx context.Context, slug string) (Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.items {
+		if c.Slug == slug {
+			return c, nil
+		}
+	}
+	return Category{}, sql.ErrNoRows
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, in Category) (Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.items[in.ID]
+	if !ok {
+		return Category{}, sql.ErrNoRows
+	}
+	if r.slugTaken(in.Slug, in.ID) {
+		return Category{}, ErrNameTaken
+	}
+	if in.ParentID != nil {
+		if _, ok := r.items[*in.ParentID]; !ok {
+			return Category{}, ErrParentNotFound
+		}
+	}
+	c.ParentID = in.ParentID
+	c.Name = in.Name
+	c.Slug = in.Slug
+	c.UpdatedAt = time.Now().Truncate(time.Second)
+	r.items[c.ID] = c
+	return c, nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.items, id)
+	return nil
+}
+
+func (r *MemoryRepository) CountChildren(ctx context.Context, id int64) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n int64
+	for _, c := range r.items {
+		if c.ParentID != nil && *c.ParentID == id {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// slugTaken reports whether another category uses slug. Caller must hold r.mu.
+func (r *MemoryRepository) slugTaken(slug string, exceptID int64) bool {
+	for id, c := range r.items {
+		if c.Slug == slug && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package category
+
+import "time"
+
+// Category groups articles. Categories form a tree through ParentID.
+type Category struct {
+	ID        int64      `json:"id"`
+	ParentID  *int64     `json:"parent_id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Children  []Category `json:"children,omitempty"`
+}
+
+// buildTree nests flat categories under their parents, keeping the input order
+func buildTree(flat []Category) []Category {
+	children := make(map[int64][]Category)
+	roots := make([]Category, 0)
+	for _, c := range flat {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+	var attach func(nodes []Category) []Category
+	attach = func(nodes []Category) []Category {
+		for i := range nodes {
+			if kids, ok := children[nodes[i].ID]; ok {
+				nodes[i].Children = attach(kids)
+			}
+		}
+		return nodes
+	}
+	return attach(roots)
+}
@@ -0,0 +1,137 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type Repository interface {
+	// Insert stores name, slug and parent of c. A slug already in use returns ErrNameTaken.
+	Insert(ctx context.Context, c Category) (Category, error)
+	// List returns every category ordered by name
+	List(ctx context.Context) ([]Category, error)
+	FindByID(ctx context.Context, id int64) (Category, error)
+	FindBySlug(ctx context.Context, slug string) (Category, error)
+	Update(ctx context.Context, c Category) (Category, error)
+	Delete(ctx context.Context, id int64) error
+	CountChildren(ctx context.Context, id int64) (int64, error)
+}
+
+const categoryColumns = `id, parent_id, name, slug, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCategory(s rowScanner) (Category, error) {
+	var c Category
+	var parentID sql.NullInt64
+	if err := s.Scan(&c.ID, &parentID, &c.Name, &c.Slug, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return Category{}, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+	return c, nil
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) *MySQLRepository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) Insert(ctx context.Context, c Category) (Category, error) {
+	q := `INSERT INTO categories (parent_id, name, slug) VALUES (?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, q, c.ParentID, c.Name, c.Slug)
+	if err != nil {
+		return Category{}, mapMySQLError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Category{}, err
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *MySQLRepository) List(ctx context.Context) ([]Category, error) {
+	q := `SELECT ` + categoryColumns + ` FROM categories ORDER BY name, id`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return []Category{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Category, 0)
+	for rows.Next() {
+		c, errScan := scanCategory(rows)
+		if errScan != nil {
+			return []Category{}, errScan
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+func (r *MySQLRepository) FindByID(ctx context.Context, id int64) (Category, error) {
+	q := `SELECT ` + categoryColumns + ` FROM categories WHERE id = ?`
+	return scanCategory(r.db.QueryRowContext(ctx, q, id))
+}
+
+func (r *MySQLRepository) FindBySlug(ctx context.Context, slug string) (Category, error) {
+	q := `SELECT ` + categoryColumns + ` FROM categories WHERE slug = ?`
+	return scanCategory(r.db.QueryRowContext(ctx, q, slug))
+}
+
+func (r *MySQLRepository) Update(ctx context.Context, c Category) (Category, error) {
+	q := `
+    UPDATE categories
+    SET parent_id = ?, name = ?, slug = ?, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ?
+    `
+	if _, err := r.db.ExecContext(ctx, q, c.ParentID, c.Name, c.Slug, c.ID); err != nil {
+		return Category{}, mapMySQLError(err)
+	}
+	return r.FindByID(ctx, c.ID)
+}
+
+func (r *MySQLRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, id)
+	if err != nil {
+		return mapMySQLError(err)
+	}
+	n, err := res.RowsAffected()
+	if err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func (r *MySQLRepository) CountChildren(ctx context.Context, id int64) (int64, error) {
+	var n int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories WHERE parent_id = ?`, id).Scan(&n)
+	return n, err
+}
+
+// mapMySQLError turns constraint violations into the package errors:
+// duplicate slug, unknown parent and rows still referencing the category.
+func mapMySQLError(err error) error {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return err
+	}
+	switch myErr.Number {
+	case 1062:
+		return ErrNameTaken
+	case 1451:
+		return ErrInUse
+	case 1452:
+		return ErrParentNotFound
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+// ArticleCounter reports how many articles use a category, so categories in
+// use are not deleted. Implemented by the article repositories.
+type ArticleCounter interface {
+	CountByCategory(ctx context.Context, categoryID int64) (int64, error)
+}
+
+type Service struct {
+	repo     Repository
+	articles ArticleCounter
+}
+
+func NewService(repo Repository, articles ArticleCounter) *Service {
+	return &Service{repo: repo, articles: articles}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateCategoryRequest) (Category, error) {
+	name := strings.TrimSpace(req.Name)
+	c := Category{Name: name, Slug: slug.Make(name)}
+	if c.Slug == "" {
+		return Category{}, ErrInvalidName
+	}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.checkParent(ctx, 0, *req.ParentID); err != nil {
+			return Category{}, err
+		}
+		c.ParentID = req.ParentID
+	}
+	return s.repo.Insert(ctx, c)
+}
+
+// List returns categories ordered by name, nested under their parents when tree is set
+func (s *Service) List(ctx context.Context, tree bool) ([]Category, error) {
+	items, err := s.repo.List(ctx)
+	if err != nil || !tree {
+		return items, err
+	}
+	return buildTree(items), nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int64) (Category, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, req UpdateCategoryRequest) (Category, error) {
+	c, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return Category{}, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		c.Name = name
+		c.Slug = slug.Make(name)
+		if c.Slug == "" {
+			return Category{}, ErrInvalidName
+		}
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			c.ParentID = nil
+		} else {
+			if err := s.checkParent(ctx, id, *req.ParentID); err != nil {
+				return Category{}, err
+			}
+			c.ParentID = req.ParentID
+		}
+	}
+	return s.repo.Update(ctx, c)
+}
+
+// Delete removes a category that has no sub categories and no articles
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return err
+	}
+	children, err := s.repo.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrHasChildren
+	}
+	used, err := s.articles.CountByCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrInUse
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Reslug sets the slug of every category to slug.Make of its name, so rows
+// slugged in SQL by migration 0008, or by an older slug.Make, are found by
+// the lookups again. A category whose new slug still belongs to another
+// category keeps its slug and is returned in skipped.
+func (s *Service) Reslug(ctx context.Context) (updated int, skipped []Category, err error) {
+	pending, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	// retry the collisions while slugs are freed, e.g. when two categories swap
+	for progress := true; progress && len(pending) > 0; pending = skipped {
+		progress, skipped = false, nil
+		for _, c := range pending {
+			next := c
+			next.Slug = slug.Make(c.Name)
+			if next.Slug == "" || next.Slug == c.Slug {
+				continue
+			}
+			if _, err := s.repo.Update(ctx, next); err != nil {
+				if err == ErrNameTaken {
+					skipped = append(skipped, c)
+					continue
+				}
+				return updated, nil, err
+			}
+			updated++
+			progress = true
+		}
+	}
+	return updated, skipped, nil
+}
+
+// checkParent makes sure parentID exists and is not id itself or one of its
+// descendants, walking up the ancestors of parentID.
+func (s *Service) checkParent(ctx context.Context, id, parentID int64) error {
+	for pid := &parentID; pid != nil; {
+		if *pid == id {
+			return ErrCycle
+		}
+		p, err := s.repo.FindByID(ctx, *pid)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrParentNotFound
+			}
+			return err
+		}
+		pid = p.ParentID
+	}
+	return nil
+}
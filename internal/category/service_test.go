@@ -0,0 +1,78 @@
+package category
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReslug(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	// slugs as migration 0008 computed them in SQL
+	seed := []Category{
+		{Name: "Café", Slug: "caf"},
+		{Name: "Tech & News", Slug: "tech-news"},
+		{Name: "Sport", Slug: "sport"},
+		// wants "tech-news", freed only once "Tech & News" is reslugged
+		{Name: "TECH news", Slug: "technews"},
+	}
+	ids := make(map[string]int64)
+	for _, c := range seed {
+		got, err := repo.Insert(ctx, c)
+		if err != nil {
+			t.Fatalf("insert %q: %v", c.Name, err)
+		}
+		ids[c.Name] = got.ID
+	}
+
+	svc := NewService(repo, nil)
+	updated, skipped, err := svc.Reslug(ctx)
+	if err != nil {
+		t.Fatalf("Reslug: %v", err)
+	}
+	if updated != 3 || len(skipped) != 0 {
+		t.Errorf("Reslug = %d, %+v, want 3 updated", updated, skipped)
+	}
+	want := map[string]string{
+		"Café":        "cafe",
+		"Tech & News": "tech-and-news",
+		"Sport":       "sport",
+		"TECH news":   "tech-news",
+	}
+	for name, slug := range want {
+		c, err := repo.FindByID(ctx, ids[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Slug != slug {
+			t.Errorf("%q: slug = %q, want %q", name, c.Slug, slug)
+		}
+		if _, err := repo.FindBySlug(ctx, slug); err != nil {
+			t.Errorf("FindBySlug(%q): %v", slug, err)
+		}
+	}
+
+	// a second run has nothing left to do
+	if updated, skipped, err := svc.Reslug(ctx); err != nil || updated != 0 || len(skipped) != 0 {
+		t.Errorf("second Reslug = %d, %v, %v", updated, skipped, err)
+	}
+}
+
+func TestReslugKeepsTakenSlug(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	// both names slug to "cafe": the second one cannot take it
+	first, _ := repo.Insert(ctx, Category{Name: "cafe", Slug: "cafe"})
+	second, _ := repo.Insert(ctx, Category{Name: "Café", Slug: "caf"})
+
+	updated, skipped, err := NewService(repo, nil).Reslug(ctx)
+	if err != nil {
+		t.Fatalf("Reslug: %v", err)
+	}
+	if updated != 0 || len(skipped) != 1 || skipped[0].ID != second.ID || skipped[0].Slug != "caf" {
+		t.Fatalf("Reslug = %d, %+v", updated, skipped)
+	}
+	if c, _ := repo.FindByID(ctx, first.ID); c.Slug != "cafe" {
+		t.Errorf("first slug = %q", c.Slug)
+	}
+}
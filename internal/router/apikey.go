@@ -0,0 +1,48 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/apikey"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+)
+
+// HeaderAPIKey carries the key of machine clients
+const HeaderAPIKey = "X-API-Key"
+
+// apiKeyAuth authenticates requests that send an API key. The key's scopes
+// become a role for the article policy. Requests without the header continue
+// unauthenticated, so user tokens still work.
+func apiKeyAuth(keys *apikey.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := strings.TrimSpace(c.Get(HeaderAPIKey))
+		if raw == "" {
+			return c.Next()
+		}
+		k, err := keys.Authenticate(c.Context(), raw)
+		if err != nil {
+			if err == apikey.ErrInvalidKey {
+				return response.Fail(c, fiber.StatusUnauthorized, err.Error())
+			}
+			return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+		}
+		auth.SetPrincipal(c, k.Principal())
+		return c.Next()
+	}
+}
+
+// requireAccountRole is auth.RequireRole for user accounts only. API key
+// scopes cover the articles; a key never manages categories, webhooks or
+// reads the audit log, whatever role its scopes map to.
+func requireAccountRole(roles ...auth.Role) fiber.Handler {
+	requireRole := auth.RequireRole(roles...)
+	return func(c *fiber.Ctx) error {
+		if p, ok := auth.PrincipalFrom(c); ok && p.APIKeyID != 0 {
+			return response.Fail(c, fiber.StatusForbidden, "API key hanya boleh mengakses artikel")
+		}
+		return requireRole(c)
+	}
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/apikey"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/article"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+// newTestApp registers every route on the memory repositories and returns an
+// API key with scopes
+func newTestApp(t *testing.T, scopes ...string) (*fiber.App, string) {
+	t.Helper()
+	categories := category.NewMemoryRepository()
+	articles := article.NewMemoryRepository(categories)
+	audits := audit.NewMemoryRepository()
+	webhooks := webhook.NewMemoryRepository()
+	keys := apikey.NewService(apikey.NewMemoryRepository())
+	_, raw, err := keys.Create(context.Background(), "test", scopes, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := validatorpkg.NewValidator()
+	authService := auth.NewService(auth.NewMemoryRepository(), "test-secret", time.Minute, time.Hour, "")
+	articleService := article.NewService(articles, categories, audits, webhooks, "test-secret")
+	app := fiber.New()
+	Register(app, authService, keys,
+		auth.NewHandler(authService, validator),
+		article.NewHandler(articleService, article.NewPolicy(article.DefaultPolicyRules), validator),
+		category.NewHandler(category.NewService(categories, articles), validator),
+		audit.NewHandler(audit.NewService(audits)),
+		webhook.NewHandler(webhook.NewService(webhooks), validator),
+	)
+	return app, raw
+}
+
+func TestAPIKeyOnlyReachesArticles(t *testing.T) {
+	app, key := newTestApp(t, apikey.ScopeAdmin)
+	cases := []struct {
+		method, path, body string
+		want               int
+	}{
+		{fiber.MethodPost, "/webhooks", `{"url": "https://example.com/hook", "events": ["article.created"]}`, fiber.StatusForbidden},
+		{fiber.MethodGet, "/webhooks", "", fiber.StatusForbidden},
+		{fiber.MethodGet, "/audit", "", fiber.StatusForbidden},
+		{fiber.MethodPost, "/categories", `{"name": "Machine"}`, fiber.StatusForbidden},
+		// public reads and the articles stay available
+		{fiber.MethodGet, "/categories", "", fiber.StatusOK},
+		{fiber.MethodGet, "/articles", "", fiber.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(HeaderAPIKey, key)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, res.StatusCode, tc.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package router
+
+import "github.com/gofiber/fiber/v2"
+
+// maxRequestIDLength matches the request_id column of the audit log
+const maxRequestIDLength = 64
+
+// validRequestID reports whether a client supplied request id is short
+// enough for the audit log and only uses [A-Za-z0-9-_.]
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// dropInvalidRequestID removes an unusable X-Request-ID from the request,
+// so the requestid middleware generates a new one instead of reusing it
+func dropInvalidRequestID(c *fiber.Ctx) error {
+	if id := c.Get(fiber.HeaderXRequestID); id != "" && !validRequestID(id) {
+		c.Request().Header.Del(fiber.HeaderXRequestID)
+	}
+	return c.Next()
+}
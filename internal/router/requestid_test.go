@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(dropInvalidRequestID)
+	app.Use(requestid.New())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	cases := []struct {
+		name, sent string
+		reused     bool
+	}{
+		{"absent", "", false},
+		{"valid", "req-1_a.B", true},
+		{"max length", strings.Repeat("a", maxRequestIDLength), true},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"space", "req 1", false},
+		{"quote", `req"1`, false},
+		{"non ascii", "réq", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tc.sent != "" {
+				req.Header.Set(fiber.HeaderXRequestID, tc.sent)
+			}
+			res, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := res.Header.Get(fiber.HeaderXRequestID)
+			if tc.reused && got != tc.sent {
+				t.Errorf("request id = %q, want %q", got, tc.sent)
+			}
+			if !tc.reused && (got == tc.sent || !validRequestID(got)) {
+				t.Errorf("request id = %q, want a new valid id", got)
+			}
+		})
+	}
+}
@@ -3,19 +3,26 @@ package router
 import (
 	"time"
 
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/apikey"
 	"github.com/ranggakrisnaa/sharing-vision-backend/internal/article"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/audit"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/auth"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/category"
+	"github.com/ranggakrisnaa/sharing-vision-backend/internal/webhook"
 	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
-func Register(app *fiber.App, articleHandler *article.Handler) {
+func Register(app *fiber.App, authService *auth.Service, apiKeyService *apikey.Service, authHandler *auth.Handler, articleHandler *article.Handler, categoryHandler *category.Handler, auditHandler *audit.Handler, webhookHandler *webhook.Handler) {
 	// CORS
 	app.Use(func(c *fiber.Ctx) error {
 		c.Set("Access-Control-Allow-Origin", "*")
 		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+HeaderAPIKey+", If-Match, If-None-Match, Last-Event-ID, "+fiber.HeaderXRequestID)
+		c.Set("Access-Control-Expose-Headers", "ETag, "+fiber.HeaderXRequestID)
 		if c.Method() == "OPTIONS" {
 			return c.SendStatus(fiber.StatusOK)
 		}
@@ -24,11 +31,15 @@ func Register(app *fiber.App, articleHandler *article.Handler) {
 
 	// Global middlewares
 	app.Use(recover.New())
+	// reuses a valid client supplied X-Request-ID, otherwise generates one
+	app.Use(dropInvalidRequestID)
+	app.Use(requestid.New())
 	app.Use(func(c *fiber.Ctx) error {
 		start := time.Now()
 		err := c.Next()
 		latency := time.Since(start)
 		logger.Log.WithFields(map[string]interface{}{
+			"request_id": c.GetRespHeader(fiber.HeaderXRequestID),
 			"ip":         c.IP(),
 			"method":     c.Method(),
 			"path":       c.Path(),
@@ -43,7 +54,18 @@ func Register(app *fiber.App, articleHandler *article.Handler) {
 		return c.SendString("OK")
 	})
 
+	app.Use(apiKeyAuth(apiKeyService))
+	requireUser := auth.RequireUser(authService)
+	requireEditor := requireAccountRole(auth.RoleAdmin, auth.RoleEditor)
+	requireAdmin := requireAccountRole(auth.RoleAdmin)
+	authHandler.Register(app.Group("/auth"))
+	authHandler.RegisterUsers(app.Group("/users"))
+
 	// Register article routes
 	articleGroup := app.Group("/articles")
-	articleHandler.Register(articleGroup)
+	articleHandler.Register(articleGroup, requireUser)
+	articleHandler.RegisterTags(app.Group("/tags"))
+	categoryHandler.Register(app.Group("/categories"), requireUser, requireEditor)
+	auditHandler.Register(app.Group("/audit"), requireUser, requireAdmin)
+	webhookHandler.Register(app.Group("/webhooks"), requireUser, requireAdmin)
 }
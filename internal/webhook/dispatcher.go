@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/logger"
+)
+
+// dispatchBatchSize caps how many events are fanned out per query and
+// deliveries attempted per round
+const dispatchBatchSize = 100
+
+// DispatcherConfig tunes delivery. Zero fields take the defaults.
+type DispatcherConfig struct {
+	// Interval between polling rounds, default 5s
+	Interval time.Duration
+	// MaxAttempts before a delivery becomes dead, default 8
+	MaxAttempts int
+	// BackoffBase is the wait after the first failed attempt, doubled after
+	// every further failure up to BackoffMax. Defaults 30s and 6h.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Timeout of one request, default 10s
+	Timeout time.Duration
+	// Client sends the requests; tests can pass the client of an httptest server
+	Client *http.Client
+}
+
+// Dispatcher delivers outbox events to the registered endpoints.
+type Dispatcher struct {
+	repo Repository
+	cfg  DispatcherConfig
+}
+
+func NewDispatcher(repo Repository, cfg DispatcherConfig) *Dispatcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 30 * time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = 6 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{}
+	}
+	return &Dispatcher{repo: repo, cfg: cfg}
+}
+
+// Run blocks until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, failed, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Log.WithError(err).Error("dispatch webhooks failed")
+		}
+		if delivered > 0 || failed > 0 {
+			logger.Log.WithFields(map[string]interface{}{"delivered": delivered, "failed": failed}).Info("webhooks dispatched")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce fans out new outbox events and makes one attempt for every due
+// delivery. It returns how many attempts succeeded and failed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (delivered, failed int, err error) {
+	for {
+		n, err := d.repo.FanOut(ctx, time.Now(), dispatchBatchSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		if n < dispatchBatchSize {
+			break
+		}
+	}
+
+	// deliveries are leased one at a time right before they are sent, so the
+	// lease only has to outlive one request and no other dispatcher sends it
+	// meanwhile
+	for i := 0; i < dispatchBatchSize; i++ {
+		attempts, err := d.repo.ClaimDue(ctx, time.Now(), 2*d.cfg.Timeout, 1)
+		if err != nil {
+			return delivered, failed, err
+		}
+		if len(attempts) == 0 {
+			break
+		}
+		ok, err := d.attempt(ctx, attempts[0])
+		switch {
+		case errors.Is(err, ErrClaimLost):
+			logger.Log.WithField("delivery_id", attempts[0].Delivery.ID).Warn("webhook delivery claimed again before its outcome was recorded")
+		case err != nil:
+			return delivered, failed, err
+		case ok:
+			delivered++
+		default:
+			failed++
+		}
+	}
+	return delivered, failed, nil
+}
+
+// attempt sends a once and records the outcome. The returned error is about
+// recording it, a failed request only makes ok false.
+func (d *Dispatcher) attempt(ctx context.Context, a Attempt) (ok bool, err error) {
+	attempts := a.Delivery.Attempts + 1
+	status, sendErr := d.send(ctx, a)
+	if sendErr == nil {
+		return true, d.repo.MarkDelivered(ctx, a.Delivery.ID, a.Claim, attempts, status, time.Now())
+	}
+
+	var next *time.Time
+	if attempts < d.cfg.MaxAttempts {
+		t := time.Now().Add(d.backoff(attempts))
+		next = &t
+	}
+	return false, d.repo.MarkFailed(ctx, a.Delivery.ID, a.Claim, attempts, status, sendErr.Error(), next)
+}
+
+// send POSTs the event envelope to the endpoint. Any 2xx response counts as delivered.
+func (d *Dispatcher) send(ctx context.Context, a Attempt) (int, error) {
+	body, err := json.Marshal(a.Event)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sharing-vision-webhooks/1")
+	req.Header.Set(HeaderEvent, a.Event.Type)
+	req.Header.Set(HeaderEventID, strconv.FormatInt(a.Event.ID, 10))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(a.Delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(a.Endpoint.Secret, time.Now(), body))
+
+	res, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drain a little so the connection can be reused
+	_, _ = io.CopyN(io.Discard, res.Body, 4096)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("endpoint merespon %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// backoff is the wait after the given number of failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.BackoffBase
+	for i := 1; i < attempts && wait < d.cfg.BackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.BackoffMax)
+}
@@ -0,0 +1,278 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+// receiver is an endpoint that records the requests it gets and answers
+// with status
+type receiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		rc.t.Errorf("read body: %v", err)
+	}
+	if err := Verify(rc.secret, r.Header.Get(HeaderSignature), body, time.Minute, time.Now()); err != nil {
+		rc.t.Errorf("signature: %v", err)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func (rc *receiver) setStatus(status int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.status = status
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func runOnce(t *testing.T, d *Dispatcher, wantDelivered, wantFailed int) {
+	t.Helper()
+	delivered, failed, err := d.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if delivered != wantDelivered || failed != wantFailed {
+		t.Fatalf("RunOnce = %d delivered, %d failed, want %d, %d", delivered, failed, wantDelivered, wantFailed)
+	}
+}
+
+func onlyDelivery(t *testing.T, repo Repository) Delivery {
+	t.Helper()
+	items, err := repo.ListDeliveries(context.Background(), DeliveryFilter{}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(items))
+	}
+	return items[0]
+}
+
+// waitDue sleeps until d is due again
+func waitDue(d Delivery) {
+	time.Sleep(time.Until(*d.NextAttemptAt) + 5*time.Millisecond)
+}
+
+func TestDispatcherRetriesUntilDeadAndReplays(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	rc := &receiver{t: t, secret: "whsec_test", status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	ep, err := repo.InsertEndpoint(ctx, Endpoint{URL: srv.URL, Secret: rc.secret, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := repo.AddEvent(ctx, Event{Type: EventArticlePublished, ArticleID: 7, Payload: json.RawMessage(`{"id":7}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const base = 40 * time.Millisecond
+	d := NewDispatcher(repo, DispatcherConfig{
+		MaxAttempts: 3,
+		BackoffBase: base,
+		BackoffMax:  time.Second,
+		Timeout:     time.Second,
+		Client:      srv.Client(),
+	})
+
+	// every failure doubles the wait: base, then 2*base
+	for attempt, wait := 1, base; attempt < 3; attempt, wait = attempt+1, wait*2 {
+		before := time.Now()
+		runOnce(t, d, 0, 1)
+		del := onlyDelivery(t, repo)
+		if del.Status != StatusPending || del.Attempts != attempt || del.LastStatusCode != http.StatusInternalServerError {
+			t.Fatalf("after attempt %d: %+v", attempt, del)
+		}
+		if got := del.NextAttemptAt.Sub(before); got < wait || got > wait+500*time.Millisecond {
+			t.Fatalf("after attempt %d: next attempt in %v, want %v", attempt, got, wait)
+		}
+		// not due yet: nothing is sent
+		runOnce(t, d, 0, 0)
+		waitDue(del)
+	}
+
+	// the last attempt makes the delivery dead
+	runOnce(t, d, 0, 1)
+	del := onlyDelivery(t, repo)
+	if del.Status != StatusDead || del.Attempts != 3 || del.NextAttemptAt != nil {
+		t.Fatalf("after last attempt: %+v", del)
+	}
+	runOnce(t, d, 0, 0)
+
+	// a replay gets a fresh attempt budget
+	rc.setStatus(http.StatusNoContent)
+	if n, err := repo.ReplayDead(ctx, ep.ID, time.Now()); err != nil || n != 1 {
+		t.Fatalf("ReplayDead = %d, %v", n, err)
+	}
+	runOnce(t, d, 1, 0)
+	del = onlyDelivery(t, repo)
+	if del.Status != StatusDelivered || del.Attempts != 1 || del.DeliveredAt == nil {
+		t.Fatalf("after replay: %+v", del)
+	}
+
+	if rc.count() != 4 {
+		t.Fatalf("receiver got %d requests, want 4", rc.count())
+	}
+	for i, r := range rc.requests {
+		if r.Header.Get(HeaderEvent) != EventArticlePublished ||
+			r.Header.Get(HeaderEventID) != strconv.FormatInt(ev.ID, 10) ||
+			r.Header.Get(HeaderDelivery) != strconv.FormatInt(del.ID, 10) {
+			t.Errorf("request %d headers: %v", i, r.Header)
+		}
+		var got Event
+		if err := json.Unmarshal(rc.bodies[i], &got); err != nil || got.ID != ev.ID || string(got.Payload) != `{"id":7}` {
+			t.Errorf("request %d body %s: %v", i, rc.bodies[i], err)
+		}
+	}
+}
+
+func TestDispatcherSendsEachDueDeliveryOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	rc := &receiver{t: t, secret: "whsec_test", status: http.StatusOK}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.InsertEndpoint(ctx, Endpoint{URL: srv.URL, Secret: rc.secret, Active: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := repo.AddEvent(ctx, Event{Type: EventArticleCreated, ArticleID: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := NewDispatcher(repo, DispatcherConfig{Client: srv.Client()})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := d.RunOnce(ctx); err != nil {
+				t.Errorf("RunOnce: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if rc.count() != 10 {
+		t.Fatalf("receiver got %d requests, want 10", rc.count())
+	}
+}
+
+func TestMarkAfterLostClaim(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	if _, err := repo.InsertEndpoint(ctx, Endpoint{URL: "http://example.com", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddEvent(ctx, Event{Type: EventArticleCreated}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if _, err := repo.FanOut(ctx, now, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.ClaimDue(ctx, now, time.Second, 10)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("ClaimDue = %v, %v", first, err)
+	}
+	// the lease is still held: nobody else gets the delivery
+	if again, _ := repo.ClaimDue(ctx, now.Add(time.Millisecond), time.Second, 10); len(again) != 0 {
+		t.Fatalf("claimed a leased delivery: %+v", again)
+	}
+	// the lease expired and a second dispatcher took over
+	second, err := repo.ClaimDue(ctx, now.Add(2*time.Second), time.Second, 10)
+	if err != nil || len(second) != 1 || second[0].Claim == first[0].Claim {
+		t.Fatalf("ClaimDue after lease = %v, %v", second, err)
+	}
+
+	id := first[0].Delivery.ID
+	if err := repo.MarkFailed(ctx, id, first[0].Claim, 1, 500, "late", nil); !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("MarkFailed with expired claim = %v, want ErrClaimLost", err)
+	}
+	if err := repo.MarkDelivered(ctx, id, second[0].Claim, 1, 200, now); err != nil {
+		t.Fatalf("MarkDelivered: %v", err)
+	}
+	if d, _ := repo.FindDelivery(ctx, id); d.Status != StatusDelivered {
+		t.Fatalf("status = %s, want delivered", d.Status)
+	}
+	if again, _ := repo.ClaimDue(ctx, now.Add(4*time.Second), time.Second, 10); len(again) != 0 {
+		t.Fatalf("claimed a delivered delivery: %+v", again)
+	}
+
+	// a replay invalidates the claim of an attempt still in flight
+	if _, err := repo.Replay(ctx, id, now); err != nil {
+		t.Fatal(err)
+	}
+	claimed, _ := repo.ClaimDue(ctx, now, time.Second, 10)
+	if _, err := repo.Replay(ctx, id, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkDelivered(ctx, id, claimed[0].Claim, 1, 200, now); !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("MarkDelivered after replay = %v, want ErrClaimLost", err)
+	}
+}
+
+func TestRolledBackEventIsNotDelivered(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	if _, err := repo.InsertEndpoint(ctx, Endpoint{URL: "http://example.com", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	failed := errors.New("later write failed")
+	err := database.WithMemTx(ctx, func(ctx context.Context) error {
+		if _, err := repo.AddEvent(ctx, Event{Type: EventArticleCreated}); err != nil {
+			return err
+		}
+		// the dispatcher may fan out before the transaction ends
+		if _, err := repo.FanOut(context.Background(), now, 10); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithMemTx = %v, want %v", err, failed)
+	}
+	if items, _ := repo.ListDeliveries(ctx, DeliveryFilter{}, 10, 0); len(items) != 0 {
+		t.Fatalf("deliveries of a rolled back event: %+v", items)
+	}
+	if n, _ := repo.FanOut(ctx, now, 10); n != 0 {
+		t.Fatalf("FanOut = %d events after rollback, want 0", n)
+	}
+}
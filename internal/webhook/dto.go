@@ -0,0 +1,15 @@
+package webhook
+
+type CreateEndpointRequest struct {
+	URL string `json:"url" validate:"required,url,max=2048"`
+	// Events to subscribe to, empty subscribes to every event
+	Events []string `json:"events" validate:"omitempty,dive,required"`
+	// Secret is generated when empty
+	Secret string `json:"secret" validate:"omitempty,min=16,max=255"`
+}
+
+// CreatedEndpoint is the response of a create, the only one carrying the secret
+type CreatedEndpoint struct {
+	Endpoint
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,13 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrInvalidURL   = errors.New("url webhook harus http atau https")
+	ErrUnknownEvent = errors.New("event tidak dikenal")
+	// ErrInvalidSignature is returned by Verify for a missing, malformed, stale or wrong signature
+	ErrInvalidSignature = errors.New("signature webhook tidak valid")
+	// ErrClaimLost means the lease of an attempt expired and the delivery was
+	// claimed again or replayed, so its outcome is no longer recorded
+	ErrClaimLost = errors.New("klaim delivery sudah tidak berlaku")
+)
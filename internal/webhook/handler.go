@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+	validatorpkg "github.com/ranggakrisnaa/sharing-vision-backend/pkg/validator"
+)
+
+type Handler struct {
+	svc       *Service
+	validator *validatorpkg.Validator
+}
+
+func NewHandler(svc *Service, validator *validatorpkg.Validator) *Handler {
+	return &Handler{svc: svc, validator: validator}
+}
+
+// Register mounts the webhook endpoints, e.g. on /webhooks. Every route runs
+// requireUser and then requireAdmin.
+func (h *Handler) Register(r fiber.Router, requireUser, requireAdmin fiber.Handler) {
+	r.Use(requireUser, requireAdmin)
+	r.Post("/", h.create)
+	r.Get("/", h.list)
+	r.Get("/deliveries", h.listDeliveries)
+	r.Post("/deliveries/:id/replay", h.replay)
+	r.Delete("/:id", h.delete)
+	r.Post("/:id/replay", h.replayDead)
+}
+
+func (h *Handler) create(c *fiber.Ctx) error {
+	var req CreateEndpointRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Fail(c, fiber.StatusBadRequest, "invalid JSON body")
+	}
+	errors, _ := h.validator.ValidateStructDetailed(req)
+	if len(errors) > 0 {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, errors)
+	}
+	ep, err := h.svc.CreateEndpoint(c.Context(), req)
+	if err != nil {
+		switch err {
+		case ErrInvalidURL:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, err.Error())
+		case ErrUnknownEvent:
+			return response.Fail(c, fiber.StatusUnprocessableEntity, fmt.Sprintf("%s: pilih %s", err.Error(), strings.Join(EventTypes, " | ")))
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusCreated, ep, "webhook created successfully, simpan secret ini karena tidak akan ditampilkan lagi")
+}
+
+func (h *Handler) list(c *fiber.Ctx) error {
+	items, err := h.svc.ListEndpoints(c.Context())
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, items, "webhooks retrieved successfully")
+}
+
+func (h *Handler) delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	if err := h.svc.DeleteEndpoint(c.Context(), id); err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "webhook tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, nil, "webhook deleted successfully")
+}
+
+// listDeliveries filters by endpoint_id, event_id and status
+func (h *Handler) listDeliveries(c *fiber.Ctx) error {
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+
+	errs := make([]validatorpkg.FieldError, 0)
+	var f DeliveryFilter
+	for key, dst := range map[string]*int64{"endpoint_id": &f.EndpointID, "event_id": &f.EventID} {
+		if v := c.Query(key); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errs = append(errs, validatorpkg.FieldError{Field: key, Message: key + " harus integer", Tag: "numeric", Param: v})
+			}
+			*dst = n
+		}
+	}
+	f.Status = strings.ToLower(c.Query("status"))
+	if f.Status != "" && !slices.Contains([]string{StatusPending, StatusDelivered, StatusDead}, f.Status) {
+		errs = append(errs, validatorpkg.FieldError{Field: "status", Message: "status invalid: pilih pending | delivered | dead", Tag: "oneof", Param: f.Status})
+	}
+	if len(errs) > 0 {
+		return response.Fail(c, fiber.StatusBadRequest, errs)
+	}
+
+	items, meta, err := h.svc.ListDeliveries(c.Context(), f, page, limit)
+	if err != nil {
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, map[string]interface{}{
+		"items": items,
+		"meta":  meta,
+	}, "deliveries retrieved successfully")
+}
+
+func (h *Handler) replay(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	d, err := h.svc.Replay(c.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "delivery tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, d, "delivery queued for replay")
+}
+
+func (h *Handler) replayDead(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.Fail(c, fiber.StatusUnprocessableEntity, "id harus integer")
+	}
+	n, err := h.svc.ReplayDead(c.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return response.Fail(c, fiber.StatusNotFound, "webhook tidak ditemukan")
+		}
+		return response.Fail(c, fiber.StatusInternalServerError, err.Error())
+	}
+	return response.Success(c, fiber.StatusOK, map[string]int64{"replayed": n}, "dead deliveries queued for replay")
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+// MemoryRepository menyimpan outbox, endpoint dan delivery di memory untuk
+// STORAGE_DRIVER=memory; semantiknya mengikuti MySQLRepository.
+type MemoryRepository struct {
+	mu             sync.RWMutex
+	nextEventID    int64
+	nextEndpointID int64
+	nextDeliveryID int64
+	events         []Event
+	// dispatched marks events that were fanned out
+	dispatched map[int64]bool
+	endpoints  map[int64]Endpoint
+	deliveries map[int64]Delivery
+	// claims holds the claim token of every leased delivery
+	claims map[int64]string
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		dispatched: make(map[int64]bool),
+		endpoints:  make(map[int64]Endpoint),
+		deliveries: make(map[int64]Delivery),
+		claims:     make(map[int64]string),
+	}
+}
+
+func memNow() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+func (r *MemoryRepository) AddEvent(ctx context.Context, e Event) (Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextEventID++
+	e.ID = r.nextEventID
+	e.CreatedAt = memNow()
+	r.events = append(r.events, e)
+	database.OnRollback(ctx, func() { r.removeEvent(e.ID) })
+	return e, nil
+}
+
+// removeEvent drops a rolled back event together with whatever FanOut
+// already made of it
+func (r *MemoryRepository) removeEvent(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = slices.DeleteFunc(r.events, func(e Event) bool { return e.ID == id })
+	delete(r.dispatched, id)
+	for did, d := range r.deliveries {
+		if d.EventID == id {
+			delete(r.deliveries, did)
+			delete(r.claims, did)
+		}
+	}
+}
+
+func (r *MemoryRepository) InsertEndpoint(ctx context.Context, e Endpoint) (Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextEndpointID++
+	now := memNow()
+	e.ID, e.CreatedAt, e.UpdatedAt = r.nextEndpointID, now, now
+	r.endpoints[e.ID] = e
+	return e, nil
+}
+
+func (r *MemoryRepository) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.sortedEndpoints(), nil
+}
+
+func (r *MemoryRepository) FindEndpoint(ctx context.Context, id int64) (Endpoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.endpoints[id]
+	if !ok {
+		return Endpoint{}, sql.ErrNoRows
+	}
+	return e, nil
+}
+
+func (r *MemoryRepository) DeleteEndpoint(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[id]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.endpoints, id)
+	for did, d := range r.deliveries {
+		if d.EndpointID == id {
+			delete(r.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) FanOut(ctx context.Context, now time.Time, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, e := range r.events {
+		if n == limit {
+			break
+		}
+		if r.dispatched[e.ID] {
+			continue
+		}
+		for _, ep := range r.sortedEndpoints() {
+			if !ep.Active || !ep.Subscribes(e.Type) {
+				continue
+			}
+			r.nextDeliveryID++
+			next := now
+			r.deliveries[r.nextDeliveryID] = Delivery{
+				ID:            r.nextDeliveryID,
+				EventID:       e.ID,
+				EventType:     e.Type,
+				EndpointID:    ep.ID,
+				Status:        StatusPending,
+				NextAttemptAt: &next,
+				CreatedAt:     memNow(),
+				UpdatedAt:     memNow(),
+			}
+		}
+		r.dispatched[e.ID] = true
+		n++
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Attempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]Delivery, 0)
+	for _, d := range r.deliveries {
+		if d.Status == StatusPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claim := newClaim()
+	res := make([]Attempt, 0, len(due))
+	for _, d := range due {
+		leased := now.Add(lease)
+		d.NextAttemptAt = &leased
+		r.deliveries[d.ID] = d
+		r.claims[d.ID] = claim
+		res = append(res, Attempt{Delivery: d, Event: r.event(d.EventID), Endpoint: r.endpoints[d.EndpointID], Claim: claim})
+	}
+	return res, nil
+}
+
+func (r *MemoryRepository) MarkDelivered(ctx context.Context, id int64, claim string, attempts, statusCode int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok || r.claims[id] != claim {
+		return ErrClaimLost
+	}
+	delete(r.claims, id)
+	d.Status, d.Attempts, d.LastStatusCode, d.LastError = StatusDelivered, attempts, statusCode, ""
+	d.DeliveredAt, d.NextAttemptAt = &at, nil
+	d.UpdatedAt = memNow()
+	r.deliveries[id] = d
+	return nil
+}
+
+func (r *MemoryRepository) MarkFailed(ctx context.Context, id int64, claim string, attempts, statusCode int, errMsg string, next *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok || r.claims[id] != claim {
+		return ErrClaimLost
+	}
+	delete(r.claims, id)
+	d.Status = StatusPending
+	if next == nil {
+		d.Status = StatusDead
+	}
+	d.Attempts, d.LastStatusCode, d.LastError, d.NextAttemptAt = attempts, statusCode, errMsg, next
+	d.UpdatedAt = memNow()
+	r.deliveries[id] = d
+	return nil
+}
+
+func (r *MemoryRepository) ListDeliveries(ctx context.Context, f DeliveryFilter, limit, offset int) ([]Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]Delivery, 0)
+	for _, d := range r.deliveries {
+		if (f.EndpointID == 0 || d.EndpointID == f.EndpointID) &&
+			(f.EventID == 0 || d.EventID == f.EventID) &&
+			(f.Status == "" || d.Status == f.Status) {
+			all = append(all, d)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+	if offset >= len(all) {
+		return []Delivery{}, nil
+	}
+	all = all[offset:]
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+func (r *MemoryRepository) FindDelivery(ctx context.Context, id int64) (Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return Delivery{}, sql.ErrNoRows
+	}
+	return d, nil
+}
+
+func (r *MemoryRepository) Replay(ctx context.Context, id int64, now time.Time) (Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return Delivery{}, sql.ErrNoRows
+	}
+	r.replay(&d, now)
+	d.DeliveredAt = nil
+	r.deliveries[id] = d
+	return d, nil
+}
+
+func (r *MemoryRepository) ReplayDead(ctx context.Context, endpointID int64, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, d := range r.deliveries {
+		if d.EndpointID == endpointID && d.Status == StatusDead {
+			r.replay(&d, now)
+			r.deliveries[id] = d
+			n++
+		}
+	}
+	return n, nil
+}
+
+// replay resets d to a fresh pending delivery due at now. Caller must hold r.mu.
+func (r *MemoryRepository) replay(d *Delivery, now time.Time) {
+	d.Status, d.Attempts, d.LastStatusCode, d.LastError = StatusPending, 0, 0, ""
+	d.NextAttemptAt = &now
+	d.UpdatedAt = memNow()
+	delete(r.claims, d.ID)
+}
+
+// event looks up an outbox event. Caller must hold r.mu.
+func (r *MemoryRepository) event(id int64) Event {
+	for _, e := range r.events {
+		if e.ID == id {
+			return e
+		}
+	}
+	return Event{}
+}
+
+// sortedEndpoints returns the endpoints in id order. Caller must hold r.mu.
+func (r *MemoryRepository) sortedEndpoints() []Endpoint {
+	res := make([]Endpoint, 0, len(r.endpoints))
+	for _, e := range r.endpoints {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
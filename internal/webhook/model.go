@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Delivery statuses. A pending delivery is retried until it is delivered or
+// runs out of attempts and becomes dead.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Event types
+const (
+	EventArticleCreated   = "article.created"
+	EventArticleUpdated   = "article.updated"
+	EventArticlePublished = "article.published"
+	EventArticleDeleted   = "article.deleted"
+)
+
+// EventTypes lists every event an endpoint can subscribe to
+var EventTypes = []string{EventArticleCreated, EventArticleUpdated, EventArticlePublished, EventArticleDeleted}
+
+// Event is an outbox entry, written in the same transaction as the change it describes
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	ArticleID int64           `json:"article_id"`
+	Payload   json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Endpoint is a registered webhook receiver
+type Endpoint struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url"`
+	// Secret signs every request; it is only shown once, on creation
+	Secret string `json:"-"`
+	// Events the endpoint subscribes to, empty means every event
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether events of type eventType go to e
+func (e Endpoint) Subscribes(eventType string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, eventType)
+}
+
+// Delivery tracks sending one event to one endpoint
+type Delivery struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	EndpointID     int64      `json:"endpoint_id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Attempt is a claimed delivery together with what is needed to send it
+type Attempt struct {
+	Delivery Delivery
+	Event    Event
+	Endpoint Endpoint
+	// Claim is the token of the lease; the outcome is recorded only while it holds
+	Claim string
+}
+
+// DeliveryFilter selects deliveries; zero fields match everything
+type DeliveryFilter struct {
+	EndpointID int64
+	EventID    int64
+	Status     string
+}
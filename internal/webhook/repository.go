@@ -0,0 +1,437 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/database"
+)
+
+type Repository interface {
+	// AddEvent writes e to the outbox. With MySQL it joins the transaction
+	// carried by ctx, so the event exists only if the change is committed.
+	AddEvent(ctx context.Context, e Event) (Event, error)
+
+	InsertEndpoint(ctx context.Context, e Endpoint) (Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]Endpoint, error)
+	FindEndpoint(ctx context.Context, id int64) (Endpoint, error)
+	// DeleteEndpoint removes the endpoint together with its deliveries
+	DeleteEndpoint(ctx context.Context, id int64) error
+
+	// FanOut creates a pending delivery per subscribed active endpoint for up
+	// to limit outbox events not fanned out yet, and returns how many events
+	// it handled.
+	FanOut(ctx context.Context, now time.Time, limit int) (int, error)
+	// ClaimDue returns up to limit pending deliveries due at now and pushes
+	// their next_attempt_at to now+lease under a new claim token, so
+	// concurrent dispatchers skip them while they are being sent.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Attempt, error)
+	// MarkDelivered records a successful attempt made under claim. Once the
+	// delivery was claimed again or replayed it returns ErrClaimLost.
+	MarkDelivered(ctx context.Context, id int64, claim string, attempts, statusCode int, at time.Time) error
+	// MarkFailed records a failed attempt like MarkDelivered; a nil next
+	// makes the delivery dead
+	MarkFailed(ctx context.Context, id int64, claim string, attempts, statusCode int, errMsg string, next *time.Time) error
+
+	ListDeliveries(ctx context.Context, f DeliveryFilter, limit, offset int) ([]Delivery, error)
+	FindDelivery(ctx context.Context, id int64) (Delivery, error)
+	// Replay makes a delivery pending again with a fresh attempt budget
+	Replay(ctx context.Context, id int64, now time.Time) (Delivery, error)
+	// ReplayDead replays every dead delivery of an endpoint and returns how many
+	ReplayDead(ctx context.Context, endpointID int64, now time.Time) (int64, error)
+}
+
+const endpointColumns = `id, url, secret, events, active, created_at, updated_at`
+
+const deliveryColumns = `d.id, d.event_id, (SELECT e.event_type FROM outbox_events e WHERE e.id = d.event_id), d.endpoint_id, d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEndpoint(s rowScanner) (Endpoint, error) {
+	var e Endpoint
+	var events string
+	if err := s.Scan(&e.ID, &e.URL, &e.Secret, &events, &e.Active, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return Endpoint{}, err
+	}
+	e.Events = splitEvents(events)
+	return e, nil
+}
+
+// scanDelivery scans deliveryColumns followed by any extra selected columns
+func scanDelivery(s rowScanner, extra ...interface{}) (Delivery, error) {
+	var d Delivery
+	var nextAttempt, deliveredAt sql.NullTime
+	var statusCode sql.NullInt64
+	var lastError sql.NullString
+	dest := []interface{}{&d.ID, &d.EventID, &d.EventType, &d.EndpointID, &d.Status, &d.Attempts, &nextAttempt, &statusCode, &lastError, &deliveredAt, &d.CreatedAt, &d.UpdatedAt}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return Delivery{}, err
+	}
+	if nextAttempt.Valid && d.Status == StatusPending {
+		d.NextAttemptAt = &nextAttempt.Time
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	d.LastStatusCode = int(statusCode.Int64)
+	d.LastError = lastError.String
+	return d, nil
+}
+
+func splitEvents(csv string) []string {
+	res := make([]string, 0)
+	for _, e := range strings.Split(csv, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+type MySQLRepository struct {
+	db *sql.DB
+}
+
+func NewMySQLRepository(db *sql.DB) *MySQLRepository {
+	return &MySQLRepository{db: db}
+}
+
+func (r *MySQLRepository) conn(ctx context.Context) database.Querier {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *MySQLRepository) AddEvent(ctx context.Context, e Event) (Event, error) {
+	q := `INSERT INTO outbox_events (event_type, article_id, payload) VALUES (?, ?, ?)`
+	res, err := r.conn(ctx).ExecContext(ctx, q, e.Type, e.ArticleID, string(e.Payload))
+	if err != nil {
+		return Event{}, err
+	}
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return Event{}, err
+	}
+	e.CreatedAt = time.Now()
+	return e, nil
+}
+
+func (r *MySQLRepository) InsertEndpoint(ctx context.Context, e Endpoint) (Endpoint, error) {
+	q := `INSERT INTO webhook_endpoints (url, secret, events, active) VALUES (?, ?, ?, ?)`
+	res, err := r.conn(ctx).ExecContext(ctx, q, e.URL, e.Secret, strings.Join(e.Events, ","), e.Active)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return r.FindEndpoint(ctx, id)
+}
+
+func (r *MySQLRepository) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, `SELECT `+endpointColumns+` FROM webhook_endpoints ORDER BY id`)
+	if err != nil {
+		return []Endpoint{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Endpoint, 0)
+	for rows.Next() {
+		e, errScan := scanEndpoint(rows)
+		if errScan != nil {
+			return []Endpoint{}, errScan
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func (r *MySQLRepository) FindEndpoint(ctx context.Context, id int64) (Endpoint, error) {
+	q := `SELECT ` + endpointColumns + ` FROM webhook_endpoints WHERE id = ?`
+	e, err := scanEndpoint(r.conn(ctx).QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Endpoint{}, sql.ErrNoRows
+		}
+		return Endpoint{}, err
+	}
+	return e, nil
+}
+
+func (r *MySQLRepository) DeleteEndpoint(ctx context.Context, id int64) error {
+	res, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *MySQLRepository) FanOut(ctx context.Context, now time.Time, limit int) (int, error) {
+	n := 0
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		q := `
+        SELECT id, event_type FROM outbox_events
+        WHERE dispatched_at IS NULL
+        ORDER BY id
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED
+        `
+		rows, err := tx.QueryContext(ctx, q, limit)
+		if err != nil {
+			return err
+		}
+		events := make([]Event, 0)
+		for rows.Next() {
+			var e Event
+			if errScan := rows.Scan(&e.ID, &e.Type); errScan != nil {
+				rows.Close()
+				return errScan
+			}
+			events = append(events, e)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(events) == 0 {
+			return err
+		}
+
+		endpoints, err := r.ListEndpoints(ctx)
+		if err != nil {
+			return err
+		}
+		ins := `INSERT IGNORE INTO webhook_deliveries (event_id, endpoint_id, next_attempt_at) VALUES (?, ?, ?)`
+		args := make([]interface{}, len(events))
+		for i, e := range events {
+			for _, ep := range endpoints {
+				if !ep.Active || !ep.Subscribes(e.Type) {
+					continue
+				}
+				if _, err := tx.ExecContext(ctx, ins, e.ID, ep.ID, now); err != nil {
+					return err
+				}
+			}
+			args[i] = e.ID
+		}
+		up := `UPDATE outbox_events SET dispatched_at = ? WHERE id IN (` + placeholders(len(args)) + `)`
+		if _, err := tx.ExecContext(ctx, up, append([]interface{}{now}, args...)...); err != nil {
+			return err
+		}
+		n = len(events)
+		return nil
+	})
+	return n, err
+}
+
+func (r *MySQLRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Attempt, error) {
+	attempts := make([]Attempt, 0)
+	err := database.WithTx(ctx, r.db, func(ctx context.Context) error {
+		tx := r.conn(ctx)
+		// SKIP LOCKED plus the lease lets several dispatchers share the queue
+		q := `
+        SELECT id FROM webhook_deliveries
+        WHERE status = 'pending' AND next_attempt_at <= ?
+        ORDER BY next_attempt_at, id
+        LIMIT ?
+        FOR UPDATE SKIP LOCKED
+        `
+		rows, err := tx.QueryContext(ctx, q, now, limit)
+		if err != nil {
+			return err
+		}
+		args := make([]interface{}, 0)
+		for rows.Next() {
+			var id int64
+			if errScan := rows.Scan(&id); errScan != nil {
+				rows.Close()
+				return errScan
+			}
+			args = append(args, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil || len(args) == 0 {
+			return err
+		}
+
+		claim := newClaim()
+		up := `UPDATE webhook_deliveries SET next_attempt_at = ?, claim_token = ? WHERE id IN (` + placeholders(len(args)) + `)`
+		if _, err := tx.ExecContext(ctx, up, append([]interface{}{now.Add(lease), claim}, args...)...); err != nil {
+			return err
+		}
+
+		sel := `
+        SELECT ` + deliveryColumns + `, e.article_id, e.payload, e.created_at, ` + prefixed("ep", endpointColumns) + `
+        FROM webhook_deliveries d
+        JOIN outbox_events e ON e.id = d.event_id
+        JOIN webhook_endpoints ep ON ep.id = d.endpoint_id
+        WHERE d.id IN (` + placeholders(len(args)) + `)
+        ORDER BY d.id
+        `
+		rows, err = tx.QueryContext(ctx, sel, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var a Attempt
+			var payload []byte
+			var events string
+			a.Delivery, err = scanDelivery(rows, &a.Event.ArticleID, &payload, &a.Event.CreatedAt,
+				&a.Endpoint.ID, &a.Endpoint.URL, &a.Endpoint.Secret, &events, &a.Endpoint.Active, &a.Endpoint.CreatedAt, &a.Endpoint.UpdatedAt)
+			if err != nil {
+				return err
+			}
+			a.Event.ID, a.Event.Type, a.Event.Payload = a.Delivery.EventID, a.Delivery.EventType, payload
+			a.Endpoint.Events = splitEvents(events)
+			a.Claim = claim
+			attempts = append(attempts, a)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *MySQLRepository) MarkDelivered(ctx context.Context, id int64, claim string, attempts, statusCode int, at time.Time) error {
+	q := `
+    UPDATE webhook_deliveries
+    SET status = 'delivered', attempts = ?, last_status_code = ?, last_error = NULL, delivered_at = ?, next_attempt_at = NULL, claim_token = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND claim_token = ?
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, attempts, statusCode, at, id, claim)
+	return claimResult(res, err)
+}
+
+func (r *MySQLRepository) MarkFailed(ctx context.Context, id int64, claim string, attempts, statusCode int, errMsg string, next *time.Time) error {
+	q := `
+    UPDATE webhook_deliveries
+    SET status = IF(? IS NULL, 'dead', 'pending'), attempts = ?, last_status_code = NULLIF(?, 0), last_error = ?, next_attempt_at = ?, claim_token = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ? AND claim_token = ?
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, next, attempts, statusCode, errMsg, next, id, claim)
+	return claimResult(res, err)
+}
+
+// claimResult turns an update guarded by a claim token that matched no row into ErrClaimLost
+func claimResult(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+func (r *MySQLRepository) ListDeliveries(ctx context.Context, f DeliveryFilter, limit, offset int) ([]Delivery, error) {
+	conds := make([]string, 0)
+	args := make([]interface{}, 0)
+	if f.EndpointID != 0 {
+		conds = append(conds, "d.endpoint_id = ?")
+		args = append(args, f.EndpointID)
+	}
+	if f.EventID != 0 {
+		conds = append(conds, "d.event_id = ?")
+		args = append(args, f.EventID)
+	}
+	if f.Status != "" {
+		conds = append(conds, "d.status = ?")
+		args = append(args, f.Status)
+	}
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d`
+	if len(conds) > 0 {
+		q += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	q += ` ORDER BY d.id DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return []Delivery{}, err
+	}
+	defer rows.Close()
+
+	res := make([]Delivery, 0)
+	for rows.Next() {
+		d, errScan := scanDelivery(rows)
+		if errScan != nil {
+			return []Delivery{}, errScan
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+func (r *MySQLRepository) FindDelivery(ctx context.Context, id int64) (Delivery, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.id = ?`
+	d, err := scanDelivery(r.conn(ctx).QueryRowContext(ctx, q, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Delivery{}, sql.ErrNoRows
+		}
+		return Delivery{}, err
+	}
+	return d, nil
+}
+
+func (r *MySQLRepository) Replay(ctx context.Context, id int64, now time.Time) (Delivery, error) {
+	// a replay may leave the row unchanged, so existence is checked up front
+	if _, err := r.FindDelivery(ctx, id); err != nil {
+		return Delivery{}, err
+	}
+	q := `
+    UPDATE webhook_deliveries
+    SET status = 'pending', attempts = 0, next_attempt_at = ?, claim_token = NULL, last_error = NULL, last_status_code = NULL, delivered_at = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE id = ?
+    `
+	if _, err := r.conn(ctx).ExecContext(ctx, q, now, id); err != nil {
+		return Delivery{}, err
+	}
+	return r.FindDelivery(ctx, id)
+}
+
+func (r *MySQLRepository) ReplayDead(ctx context.Context, endpointID int64, now time.Time) (int64, error) {
+	q := `
+    UPDATE webhook_deliveries
+    SET status = 'pending', attempts = 0, next_attempt_at = ?, claim_token = NULL, last_error = NULL, last_status_code = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE endpoint_id = ? AND status = 'dead'
+    `
+	res, err := r.conn(ctx).ExecContext(ctx, q, now, endpointID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// newClaim returns a random claim token for a lease
+func newClaim() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// prefixed qualifies every column of a column list with table alias t
+func prefixed(t, columns string) string {
+	cols := strings.Split(columns, ", ")
+	for i, c := range cols {
+		cols[i] = t + "." + c
+	}
+	return strings.Join(cols, ", ")
+}
@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/response"
+)
+
+// maxLimit caps the page size of ListDeliveries
+const maxLimit = 100
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// CreateEndpoint registers a receiver. The secret is generated unless given
+// and is only returned here.
+func (s *Service) CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (CreatedEndpoint, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return CreatedEndpoint{}, ErrInvalidURL
+	}
+	events := make([]string, 0, len(req.Events))
+	for _, e := range req.Events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !slices.Contains(EventTypes, e) {
+			return CreatedEndpoint{}, ErrUnknownEvent
+		}
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = newSecret(); err != nil {
+			return CreatedEndpoint{}, err
+		}
+	}
+
+	ep, err := s.repo.InsertEndpoint(ctx, Endpoint{URL: req.URL, Secret: secret, Events: events, Active: true})
+	if err != nil {
+		return CreatedEndpoint{}, err
+	}
+	return CreatedEndpoint{Endpoint: ep, Secret: secret}, nil
+}
+
+func (s *Service) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	return s.repo.ListEndpoints(ctx)
+}
+
+func (s *Service) DeleteEndpoint(ctx context.Context, id int64) error {
+	return s.repo.DeleteEndpoint(ctx, id)
+}
+
+// ListDeliveries returns one page of deliveries matching f, newest first
+func (s *Service) ListDeliveries(ctx context.Context, f DeliveryFilter, page, limit int) ([]Delivery, response.Meta, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	limit = min(limit, maxLimit)
+	page = max(page, 1)
+
+	// fetch one extra row to know whether another page exists
+	items, err := s.repo.ListDeliveries(ctx, f, limit+1, (page-1)*limit)
+	if err != nil {
+		return []Delivery{}, response.Meta{}, err
+	}
+	hasNext := len(items) > limit
+	if hasNext {
+		items = items[:limit]
+	}
+	return items, response.Meta{Limit: limit, Page: page, HasNext: hasNext}, nil
+}
+
+// Replay queues a delivery, dead or not, to be sent again right away
+func (s *Service) Replay(ctx context.Context, id int64) (Delivery, error) {
+	return s.repo.Replay(ctx, id, time.Now())
+}
+
+// ReplayDead queues every dead delivery of an endpoint again
+func (s *Service) ReplayDead(ctx context.Context, endpointID int64) (int64, error) {
+	if _, err := s.repo.FindEndpoint(ctx, endpointID); err != nil {
+		return 0, err
+	}
+	return s.repo.ReplayDead(ctx, endpointID, time.Now())
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
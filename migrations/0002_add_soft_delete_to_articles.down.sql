@@ -0,0 +1,5 @@
+ALTER TABLE articles
+    DROP INDEX idx_articles_status_trashed_at,
+    DROP COLUMN trashed_by,
+    DROP COLUMN trashed_at,
+    DROP COLUMN previous_status;
@@ -0,0 +1,5 @@
+ALTER TABLE articles
+    ADD COLUMN previous_status VARCHAR(20) NULL AFTER status,
+    ADD COLUMN trashed_at TIMESTAMP NULL DEFAULT NULL AFTER previous_status,
+    ADD COLUMN trashed_by VARCHAR(100) NULL AFTER trashed_at,
+    ADD INDEX idx_articles_status_trashed_at (status, trashed_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS article_revisions (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    article_id BIGINT NOT NULL,
+    revision INT NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    content TEXT NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_article_revisions_article_revision (article_id, revision),
+    CONSTRAINT fk_article_revisions_article FOREIGN KEY (article_id) REFERENCES articles (id) ON DELETE CASCADE
+);
+
+-- existing articles start their history at revision 1
+INSERT INTO article_revisions (article_id, revision, title, content, category, status, created_at)
+SELECT id, 1, title, content, category, status, updated_at FROM articles;
@@ -0,0 +1,8 @@
+ALTER TABLE articles
+    ADD COLUMN version INT NOT NULL DEFAULT 1 AFTER status;
+
+-- revisions are numbered by articles.version; start every article at its
+-- latest revision so the next number is unused
+UPDATE articles a
+JOIN (SELECT article_id, MAX(revision) AS revision FROM article_revisions GROUP BY article_id) r ON r.article_id = a.id
+SET a.version = r.revision;
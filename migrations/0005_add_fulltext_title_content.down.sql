@@ -0,0 +1,2 @@
+ALTER TABLE articles
+    DROP INDEX ft_articles_title_content;
@@ -0,0 +1,2 @@
+ALTER TABLE articles
+    ADD FULLTEXT INDEX ft_articles_title_content (title, content);
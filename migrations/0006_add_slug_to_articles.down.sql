@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS article_slugs;
+
+ALTER TABLE articles
+    DROP INDEX uq_articles_slug,
+    DROP COLUMN slug;
@@ -0,0 +1,20 @@
+ALTER TABLE articles
+    ADD COLUMN slug VARCHAR(255) NULL AFTER title;
+
+-- existing rows get an ASCII slug; the id suffix keeps them unique
+UPDATE articles
+SET slug = CONCAT(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(title, '[^A-Za-z0-9]+', '-'))), '-', id);
+
+ALTER TABLE articles
+    MODIFY slug VARCHAR(255) NOT NULL,
+    ADD UNIQUE INDEX uq_articles_slug (slug);
+
+-- previous slugs of an article, used to redirect old links
+CREATE TABLE IF NOT EXISTS article_slugs (
+    slug VARCHAR(255) NOT NULL,
+    article_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (slug),
+    INDEX idx_article_slugs_article (article_id),
+    CONSTRAINT fk_article_slugs_article FOREIGN KEY (article_id) REFERENCES articles (id) ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+ALTER TABLE articles
+    ADD COLUMN category VARCHAR(100) NULL AFTER content;
+
+UPDATE articles a
+JOIN categories c ON c.id = a.category_id
+SET a.category = c.name;
+
+ALTER TABLE articles
+    DROP FOREIGN KEY fk_articles_category;
+
+ALTER TABLE articles
+    DROP INDEX idx_articles_category_status,
+    DROP COLUMN category_id,
+    MODIFY category VARCHAR(100) NOT NULL,
+    ADD INDEX idx_articles_category_status (category, status);
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    parent_id BIGINT NULL,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(120) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX uq_categories_slug (slug),
+    INDEX idx_categories_parent (parent_id),
+    CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id)
+);
+
+-- one category per distinct slug, so "Tech" and "tech" collapse into one row
+INSERT INTO categories (name, slug)
+SELECT MIN(category), category_slug
+FROM (
+    SELECT category,
+        COALESCE(NULLIF(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(category, '[^A-Za-z0-9]+', '-'))), ''), 'uncategorized') AS category_slug
+    FROM articles
+) src
+GROUP BY category_slug;
+
+ALTER TABLE articles
+    ADD COLUMN category_id BIGINT NULL AFTER content;
+
+UPDATE articles a
+JOIN categories c
+    ON c.slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(a.category, '[^A-Za-z0-9]+', '-'))), ''), 'uncategorized')
+SET a.category_id = c.id;
+
+ALTER TABLE articles
+    DROP INDEX idx_articles_category_status,
+    DROP COLUMN category,
+    MODIFY category_id BIGINT NOT NULL,
+    ADD INDEX idx_articles_category_status (category_id, status);
+
+ALTER TABLE articles
+    ADD CONSTRAINT fk_articles_category FOREIGN KEY (category_id) REFERENCES categories (id);
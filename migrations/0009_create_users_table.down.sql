@@ -0,0 +1,7 @@
+ALTER TABLE articles
+    DROP FOREIGN KEY fk_articles_author;
+ALTER TABLE articles
+    DROP INDEX idx_articles_author,
+    DROP COLUMN author_id;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ADD COLUMN role ENUM('admin','editor','writer','reader') NOT NULL DEFAULT 'reader' AFTER password_hash;
+
+-- users registered before roles existed keep being able to write
+UPDATE users SET role = 'writer';
@@ -0,0 +1,8 @@
+-- scheduled articles fall back to draft
+UPDATE articles SET status = 'draft' WHERE status = 'scheduled';
+UPDATE articles SET previous_status = 'draft' WHERE previous_status = 'scheduled';
+
+ALTER TABLE articles
+    DROP INDEX idx_articles_status_publish_at,
+    DROP COLUMN publish_at,
+    MODIFY status ENUM('publish','draft','thrash') NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE articles
+    MODIFY status ENUM('publish','draft','thrash','scheduled') NOT NULL,
+    ADD COLUMN publish_at TIMESTAMP NULL DEFAULT NULL AFTER status,
+    ADD INDEX idx_articles_status_publish_at (status, publish_at);
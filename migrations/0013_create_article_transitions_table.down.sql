@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS article_transitions;
+
+-- articles waiting for review go back to draft
+UPDATE articles SET status = 'draft' WHERE status = 'review';
+UPDATE articles SET previous_status = 'draft' WHERE previous_status = 'review';
+
+ALTER TABLE articles
+    MODIFY status ENUM('publish','draft','thrash','scheduled') NOT NULL;
@@ -0,0 +1,17 @@
+ALTER TABLE articles
+    MODIFY status ENUM('publish','draft','thrash','scheduled','review') NOT NULL;
+
+-- workflow history: every status change with the reviewer comment
+CREATE TABLE IF NOT EXISTS article_transitions (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    article_id BIGINT NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    comment TEXT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_article_transitions_article (article_id, id),
+    CONSTRAINT fk_article_transitions_article FOREIGN KEY (article_id) REFERENCES articles (id) ON DELETE CASCADE
+);
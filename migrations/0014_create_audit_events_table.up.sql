@@ -0,0 +1,17 @@
+-- article_id has no foreign key: events must outlive the articles they describe
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    article_id BIGINT NOT NULL,
+    before_data JSON NULL,
+    after_data JSON NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX idx_audit_events_article (article_id, id),
+    INDEX idx_audit_events_actor (actor, id),
+    INDEX idx_audit_events_action (action, id),
+    INDEX idx_audit_events_created_at (created_at)
+);
@@ -0,0 +1,47 @@
+-- transactional outbox: written in the same transaction as the article change
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    event_type VARCHAR(50) NOT NULL,
+    article_id BIGINT NOT NULL,
+    payload JSON NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- set once deliveries were created for every subscribed endpoint
+    dispatched_at TIMESTAMP NULL DEFAULT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_outbox_events_dispatched (dispatched_at, id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    -- comma separated event types, empty = every event
+    events VARCHAR(255) NOT NULL DEFAULT '',
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    event_id BIGINT NOT NULL,
+    endpoint_id BIGINT NOT NULL,
+    status ENUM('pending','delivered','dead') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL DEFAULT NULL,
+    -- identifies the lease of the dispatcher sending the delivery, so a
+    -- dispatcher whose lease expired cannot overwrite the outcome of the next
+    claim_token CHAR(32) NULL DEFAULT NULL,
+    last_status_code INT NULL,
+    last_error TEXT NULL,
+    delivered_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_webhook_deliveries_event_endpoint (event_id, endpoint_id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    INDEX idx_webhook_deliveries_endpoint (endpoint_id, status),
+    CONSTRAINT fk_webhook_deliveries_event FOREIGN KEY (event_id) REFERENCES outbox_events (id) ON DELETE CASCADE,
+    CONSTRAINT fk_webhook_deliveries_endpoint FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints (id) ON DELETE CASCADE
+);
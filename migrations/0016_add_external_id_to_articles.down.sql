@@ -0,0 +1,3 @@
+ALTER TABLE articles
+    DROP INDEX uq_articles_external_id,
+    DROP COLUMN external_id;
@@ -0,0 +1,4 @@
+-- id of the article in the system it was imported from, used to upsert re-imports
+ALTER TABLE articles
+    ADD COLUMN external_id VARCHAR(191) NULL DEFAULT NULL AFTER slug,
+    ADD UNIQUE INDEX uq_articles_external_id (external_id);
@@ -0,0 +1,5 @@
+-- format of content: plain, markdown or html; rendered to sanitized HTML on read
+ALTER TABLE articles
+    ADD COLUMN content_format VARCHAR(16) NOT NULL DEFAULT 'plain' AFTER content;
+ALTER TABLE article_revisions
+    ADD COLUMN content_format VARCHAR(16) NOT NULL DEFAULT 'plain' AFTER content;
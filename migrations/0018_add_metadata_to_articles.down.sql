@@ -0,0 +1,4 @@
+ALTER TABLE articles
+    DROP COLUMN excerpt,
+    DROP COLUMN reading_time,
+    DROP COLUMN word_count;
@@ -0,0 +1,18 @@
+-- metadata computed from the rendered content on every create and update,
+-- so listings do not need to load content
+ALTER TABLE articles
+    ADD COLUMN word_count INT UNSIGNED NOT NULL DEFAULT 0 AFTER content_format,
+    ADD COLUMN reading_time INT UNSIGNED NOT NULL DEFAULT 0 AFTER word_count,
+    ADD COLUMN excerpt VARCHAR(255) NOT NULL DEFAULT '' AFTER reading_time;
+
+-- existing articles are all plain text: approximate the values from the raw
+-- content, the next update stores the exact ones. Every value is computed
+-- from content in one statement so excerpt never holds more than fits.
+UPDATE articles
+SET word_count = IF(TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' ')) = '', 0,
+        CHAR_LENGTH(TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' ')))
+        - CHAR_LENGTH(REPLACE(TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' ')), ' ', '')) + 1),
+    reading_time = CEIL(word_count / 200),
+    excerpt = IF(CHAR_LENGTH(TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' '))) > 200,
+        CONCAT(LEFT(TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' ')), 199), '…'),
+        TRIM(REGEXP_REPLACE(content, '[[:space:]]+', ' ')));
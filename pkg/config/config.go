@@ -3,7 +3,9 @@ package config
 import (
     "log"
     "os"
+    "strconv"
     "strings"
+    "time"
 
     "github.com/joho/godotenv"
 )
@@ -12,18 +14,56 @@ type Config struct {
     Port        string
     DatabaseURL string
     AppEnv      string
+    // StorageDriver memilih implementasi repository: "mysql" (default) atau "memory"
+    StorageDriver string
+    // TrashRetention berapa lama artikel di trash sebelum dihapus permanen, 0 = nonaktif
+    TrashRetention     time.Duration
+    TrashPurgeInterval time.Duration
+    // SchedulerInterval seberapa sering artikel scheduled yang sudah jatuh tempo dipublish
+    SchedulerInterval time.Duration
+    // CursorSecret menandatangani cursor pagination, kosong = random per proses
+    CursorSecret string
+    // JWTSecret menandatangani access & refresh token, kosong = random per proses
+    JWTSecret       string
+    AccessTokenTTL  time.Duration
+    RefreshTokenTTL time.Duration
+    // WebhookInterval seberapa sering outbox dikirim ke webhook, WebhookMaxAttempts
+    // batas percobaan sebelum delivery menjadi dead
+    WebhookInterval    time.Duration
+    WebhookMaxAttempts int
+    WebhookTimeout     time.Duration
+    // BodyLimitMB batas ukuran body request (MB), termasuk file import artikel
+    BodyLimitMB int
+    // AdminEmail akun yang didaftarkan (atau dipromosikan saat start) sebagai admin
+    AdminEmail string
 }
 
 func Load() Config {
     _ = godotenv.Load()
     cfg := Config{
-        Port:        getEnv("PORT", "8080"),
-        DatabaseURL: getEnv("DATABASE_URL", ""),
-        AppEnv:      getEnv("APP_ENV", "development"),
+        Port:               getEnv("PORT", "8080"),
+        DatabaseURL:        getEnv("DATABASE_URL", ""),
+        AppEnv:             getEnv("APP_ENV", "development"),
+        StorageDriver:      strings.ToLower(getEnv("STORAGE_DRIVER", "mysql")),
+        TrashRetention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+        TrashPurgeInterval: getDuration("TRASH_PURGE_INTERVAL", time.Hour),
+        SchedulerInterval:  getDuration("SCHEDULER_INTERVAL", 30*time.Second),
+        CursorSecret:       getEnv("CURSOR_SECRET", ""),
+        JWTSecret:          getEnv("JWT_SECRET", ""),
+        AccessTokenTTL:     getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+        RefreshTokenTTL:    getDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+        WebhookInterval:    getDuration("WEBHOOK_INTERVAL", 5*time.Second),
+        WebhookMaxAttempts: getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+        WebhookTimeout:     getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+        BodyLimitMB:        getInt("BODY_LIMIT_MB", 32),
+        AdminEmail:         getEnv("ADMIN_EMAIL", ""),
     }
-    if strings.TrimSpace(cfg.DatabaseURL) == "" {
+    if cfg.StorageDriver == "mysql" && strings.TrimSpace(cfg.DatabaseURL) == "" {
         log.Println("Warning: DATABASE_URL is empty")
     }
+    if cfg.JWTSecret == "" {
+        log.Println("Warning: JWT_SECRET is empty, tokens will not survive a restart")
+    }
     return cfg
 }
 
@@ -32,4 +72,30 @@ func getEnv(key, def string) string {
         return v
     }
     return def
+}
+
+func getInt(key string, def int) int {
+    v := os.Getenv(key)
+    if v == "" {
+        return def
+    }
+    n, err := strconv.Atoi(v)
+    if err != nil {
+        log.Printf("Warning: invalid %s %q, using %d", key, v, def)
+        return def
+    }
+    return n
+}
+
+func getDuration(key string, def time.Duration) time.Duration {
+    v := os.Getenv(key)
+    if v == "" {
+        return def
+    }
+    d, err := time.ParseDuration(v)
+    if err != nil {
+        log.Printf("Warning: invalid %s %q, using %s", key, v, def)
+        return def
+    }
+    return d
 }
\ No newline at end of file
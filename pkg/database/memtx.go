@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+// memTx collects how to undo the writes of the memory repositories made in
+// one transaction
+type memTx struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+type memTxKey struct{}
+
+// WithMemTx is WithTx for STORAGE_DRIVER=memory. Writes are applied right
+// away; when fn fails the undo functions registered with OnRollback run in
+// reverse order. A call nested in another WithMemTx joins the outer one.
+func WithMemTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InMemTx(ctx) {
+		return fn(ctx)
+	}
+	tx := &memTx{}
+	err := fn(context.WithValue(ctx, memTxKey{}, tx))
+	if err != nil {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+	}
+	return err
+}
+
+// InMemTx reports whether ctx carries a WithMemTx transaction
+func InMemTx(ctx context.Context) bool {
+	_, ok := ctx.Value(memTxKey{}).(*memTx)
+	return ok
+}
+
+// OnRollback registers undo with the WithMemTx transaction in ctx. Outside a
+// transaction a write is final and undo is dropped.
+func OnRollback(ctx context.Context, undo func()) {
+	tx, ok := ctx.Value(memTxKey{}).(*memTx)
+	if !ok {
+		return
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.undo = append(tx.undo, undo)
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier adalah bagian dari *sql.DB dan *sql.Tx yang dipakai repository,
+// sehingga query yang sama bisa jalan di dalam maupun di luar transaksi.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// Conn returns the transaction WithTx stored in ctx, or db outside a transaction
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithTx runs fn in a transaction carried by the ctx passed to fn and commits
+// when fn returns nil. A call nested in another WithTx joins the outer
+// transaction, so repositories of different packages can write atomically.
+func WithTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
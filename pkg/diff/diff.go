@@ -0,0 +1,103 @@
+package diff
+
+import "strings"
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns a line-level diff that turns a into b, using Myers' algorithm
+// so large articles with few edits stay cheap.
+func Lines(a, b string) []Line {
+	return compute(splitLines(a), splitLines(b))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+func compute(a, b []string) []Line {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return []Line{}
+	}
+
+	offset := total
+	v := make([]int, 2*total+2)
+	trace := make([][]int, 0)
+
+	// forward pass: record V for every edit distance d until both ends meet
+	found := false
+	for d := 0; d <= total && !found; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// backtrack from (n, m) to (0, 0)
+	res := make([]Line, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && vd[offset+k-1] < vd[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vd[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			res = append(res, Line{Op: OpEqual, Text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				res = append(res, Line{Op: OpInsert, Text: b[y]})
+			} else {
+				x--
+				res = append(res, Line{Op: OpDelete, Text: a[x]})
+			}
+		}
+	}
+
+	// reverse into forward order
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+// Package jwt implements the HS256 subset of JSON Web Tokens (RFC 7519)
+// needed by the API: signing claims and verifying tokens it issued itself.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("jwt: malformed token")
+	ErrSignature = errors.New("jwt: invalid signature")
+	ErrExpired   = errors.New("jwt: token expired")
+)
+
+// header is fixed; tokens with any other alg are rejected by Parse
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// RegisteredClaims holds the standard claims used by this API. Embed it in
+// custom claim structs.
+type RegisteredClaims struct {
+	Subject   string `json:"sub,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+func (c RegisteredClaims) expiry() int64 { return c.ExpiresAt }
+
+type expirer interface {
+	expiry() int64
+}
+
+// Sign encodes claims and signs them with secret
+func Sign(claims interface{}, secret []byte) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(unsigned, secret)), nil
+}
+
+// Parse verifies token and decodes its payload into claims, which must be a
+// pointer. When claims embeds RegisteredClaims an expired token returns ErrExpired.
+func Parse(token string, secret []byte, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return ErrMalformed
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrMalformed
+	}
+	if !hmac.Equal(sig, sign(parts[0]+"."+parts[1], secret)) {
+		return ErrSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return ErrMalformed
+	}
+	if e, ok := claims.(expirer); ok && e.expiry() != 0 && time.Now().Unix() >= e.expiry() {
+		return ErrExpired
+	}
+	return nil
+}
+
+func sign(unsigned string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,218 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	xhtml "golang.org/x/net/html"
+
+	"github.com/ranggakrisnaa/sharing-vision-backend/pkg/slug"
+)
+
+// Content formats
+const (
+	FormatPlain    = "plain"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Formats lists every supported content format
+var Formats = []string{FormatPlain, FormatMarkdown, FormatHTML}
+
+// ExcerptLength is the maximum length of an excerpt in characters
+const ExcerptLength = 200
+
+var ErrUnknownFormat = errors.New("content_format tidak dikenal: pilih plain | markdown | html")
+
+// Heading is one entry of a table of contents; ID is the anchor of the heading
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Document is content rendered to HTML that is safe to embed in a page
+type Document struct {
+	HTML    string    `json:"html"`
+	TOC     []Heading `json:"toc"`
+	Excerpt string    `json:"excerpt"`
+	// Words counts the words of the text, headings included
+	Words int `json:"words"`
+}
+
+// markdown renders GitHub flavored Markdown. Raw HTML is kept, the
+// sanitizer removes whatever is unsafe in it.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+)
+
+// policy allows the markup of user generated content; scripts, styles,
+// event handlers and javascript: URLs are removed
+var policy = bluemonday.UGCPolicy()
+
+// Render converts content written in format to sanitized HTML. Headings get
+// an id derived from their text and are listed in the table of contents.
+func Render(content, format string) (Document, error) {
+	var src string
+	switch format {
+	case FormatPlain:
+		src = plainToHTML(content)
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(content), &buf); err != nil {
+			return Document{}, err
+		}
+		src = buf.String()
+	case FormatHTML:
+		src = content
+	default:
+		return Document{}, ErrUnknownFormat
+	}
+	return outline(policy.Sanitize(src))
+}
+
+// plainToHTML escapes text, turning blank line separated blocks into
+// paragraphs and the other line breaks into <br>
+func plainToHTML(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var b strings.Builder
+	for _, block := range strings.Split(text, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(block), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// blockElements separate words in the text of the excerpt
+var blockElements = map[string]bool{
+	"p": true, "br": true, "div": true, "li": true, "ul": true, "ol": true, "dl": true, "dt": true, "dd": true,
+	"blockquote": true, "pre": true, "hr": true, "table": true, "tr": true, "td": true, "th": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "figure": true, "figcaption": true,
+}
+
+// headingLevel returns 1 to 6 for h1 to h6 and 0 for any other element
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// outline adds ids to the headings of sanitized HTML and collects the
+// table of contents and the excerpt
+func outline(src string) (Document, error) {
+	doc := Document{TOC: []Heading{}}
+	var out, text, heading, headingText strings.Builder
+	var open *xhtml.Token
+	ids := make(map[string]int)
+
+	z := xhtml.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return Document{}, err
+			}
+			break
+		}
+		tok := z.Token()
+		switch tt {
+		case xhtml.StartTagToken:
+			if open == nil && headingLevel(tok.Data) > 0 {
+				open = &tok
+				heading.Reset()
+				headingText.Reset()
+				continue
+			}
+		case xhtml.EndTagToken:
+			if open != nil && tok.Data == open.Data {
+				h := Heading{Level: headingLevel(open.Data), Text: strings.Join(strings.Fields(headingText.String()), " ")}
+				h.ID = uniqueID(h.Text, ids)
+				// the generated id replaces one written by the author
+				attrs := open.Attr[:0]
+				for _, a := range open.Attr {
+					if a.Key != "id" {
+						attrs = append(attrs, a)
+					}
+				}
+				open.Attr = append(attrs, xhtml.Attribute{Key: "id", Val: h.ID})
+				out.WriteString(open.String())
+				out.WriteString(heading.String())
+				out.WriteString(tok.String())
+				doc.TOC = append(doc.TOC, h)
+				doc.Words += len(strings.Fields(h.Text))
+				open = nil
+				continue
+			}
+		case xhtml.TextToken:
+			// headings are listed in the table of contents, not in the excerpt
+			if open != nil {
+				headingText.WriteString(tok.Data)
+			} else {
+				text.WriteString(tok.Data)
+			}
+		}
+		if (tt == xhtml.StartTagToken || tt == xhtml.EndTagToken || tt == xhtml.SelfClosingTagToken) && blockElements[tok.Data] {
+			text.WriteByte(' ')
+		}
+		if open != nil {
+			heading.WriteString(tok.String())
+		} else {
+			out.WriteString(tok.String())
+		}
+	}
+	if open != nil {
+		// a heading left open until the end is kept without an anchor
+		out.WriteString(open.String())
+		out.WriteString(heading.String())
+	}
+
+	doc.HTML = out.String()
+	doc.Words += len(strings.Fields(text.String()))
+	doc.Excerpt = Excerpt(text.String(), ExcerptLength)
+	return doc, nil
+}
+
+// uniqueID slugs text into an anchor, numbering repeated headings
+func uniqueID(text string, ids map[string]int) string {
+	id := slug.Make(text)
+	if id == "" {
+		id = "section"
+	}
+	n := ids[id]
+	ids[id] = n + 1
+	if n == 0 {
+		return id
+	}
+	return id + "-" + strconv.Itoa(n)
+}
+
+// Excerpt collapses the whitespace of text and shortens it to at most max
+// characters, cutting at a word boundary and ending with an ellipsis
+func Excerpt(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)[:max-1]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:-") + "…"
+}
@@ -8,10 +8,12 @@ import (
 )
 
 type Meta struct {
-	Limit   int   `json:"limit"`
-	Page    int   `json:"page"`
-	Total   int64 `json:"total"`
-	HasNext bool  `json:"has_next"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type Response struct {
@@ -50,6 +52,15 @@ func Fail(ctx *fiber.Ctx, status int, msg interface{}) error {
 	})
 }
 
+// FailWithErrors responds with an error message together with error details
+func FailWithErrors(ctx *fiber.Ctx, status int, msg string, errors []interface{}) error {
+	return ctx.Status(status).JSON(Response{
+		Success: false,
+		Error:   msg,
+		Errors:  errors,
+	})
+}
+
 func PageMeta(limit, offset int, total int64) *Meta {
 	page := 1
 	if limit > 0 {
@@ -58,7 +69,7 @@ func PageMeta(limit, offset int, total int64) *Meta {
 	return &Meta{
 		Limit:   limit,
 		Page:    page,
-		Total:   total,
+		Total:   &total,
 		HasNext: int64(offset+limit) < total,
 	}
 }
@@ -0,0 +1,103 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength keeps slugs well below the VARCHAR(255) column, leaving room for collision suffixes
+const MaxLength = 200
+
+// transliterations of lowercase letters that do not decompose into ASCII +
+// combining marks. Accented Greek and Cyrillic letters are looked up by their
+// base letter.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d",
+	'ł': "l", 'þ': "th", 'ı': "i",
+	'&': "and",
+
+	// Cyrillic: Russian, Ukrainian, Belarusian, Serbian and Macedonian
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g", 'ў': "u",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+	'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Make turns s into a lowercase, hyphen separated slug. Latin, Cyrillic and
+// Greek letters are transliterated to ASCII; letters of other scripts, such
+// as CJK, are kept as they are.
+// "Crème Brûlée & Co." becomes "creme-brulee-and-co", "Привет, мир" becomes
+// "privet-mir" and "東京 ニュース" becomes "東京-ニュース".
+func Make(s string) string {
+	var b strings.Builder
+	hyphen := false
+	// whether the last rune written was a letter kept as is, whose combining
+	// marks belong to it
+	kept := false
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		hyphen = false
+		b.WriteString(s)
+	}
+	for _, r := range norm.NFC.String(s) {
+		r = unicode.ToLower(r)
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			kept = false
+			continue
+		}
+		// drop the combining accents of a decomposed letter
+		base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+		if t, ok := transliterations[base]; ok {
+			write(t)
+			kept = false
+			continue
+		}
+		switch {
+		case (base >= 'a' && base <= 'z') || (base >= '0' && base <= '9'):
+			write(string(base))
+			kept = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			write(string(r))
+			kept = true
+		case unicode.Is(unicode.M, r) && kept:
+			// vowel signs and viramas of e.g. Devanagari are part of the word
+			b.WriteRune(r)
+		default:
+			hyphen = true
+			kept = false
+		}
+	}
+
+	res := b.String()
+	if len(res) > MaxLength {
+		cut := MaxLength
+		for cut > 0 && !utf8.RuneStart(res[cut]) {
+			cut--
+		}
+		res = res[:cut]
+		if i := strings.LastIndexByte(res, '-'); i > MaxLength/2 {
+			res = res[:i]
+		}
+		res = strings.Trim(res, "-")
+	}
+	return res
+}
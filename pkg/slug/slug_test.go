@@ -0,0 +1,42 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMake(t *testing.T) {
+	cases := map[string]string{
+		"Crème Brûlée & Co.":  "creme-brulee-and-co",
+		"  Hello,   World!  ": "hello-world",
+		"Straße Øresund Łódź": "strasse-oresund-lodz",
+		"Привет, мир":         "privet-mir",
+		"Ёлка и Йошкар-Ола":   "yolka-i-yoshkar-ola",
+		"Об'єднання Їжака":    "ob-yednannya-yizhaka",
+		"Подъезд":             "podezd",
+		"Αθήνα Ελλάδα":        "athina-ellada",
+		"Ψυχή":                "psychi",
+		"東京 ニュース":             "東京-ニュース",
+		"한국어 제목":              "한국어-제목",
+		"हिन्दी समाचार":       "हिन्दी-समाचार",
+		"Go 1.24 — 日本語":       "go-1-24-日本語",
+		"!!!":                 "",
+		"":                    "",
+	}
+	for in, want := range cases {
+		if got := Make(in); got != want {
+			t.Errorf("Make(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMakeTruncatesOnRuneBoundary(t *testing.T) {
+	got := Make(strings.Repeat("日本", 80))
+	if len(got) > MaxLength || !utf8.ValidString(got) {
+		t.Errorf("Make = %d bytes, valid %v", len(got), utf8.ValidString(got))
+	}
+	if got := Make(strings.Repeat("word ", 60)); len(got) > MaxLength || strings.HasSuffix(got, "-") || strings.HasSuffix(got, "wor") {
+		t.Errorf("Make = %q", got)
+	}
+}